@@ -0,0 +1,74 @@
+// Copyright 2024 Kirill Scherba <kirill@scherba.ru>. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Config helper go package. Clone module makes deep copies of config values.
+
+package conf
+
+import "reflect"
+
+// Clone returns a deep copy of v. Pointers, slices, maps and interfaces are
+// copied recursively, so changing the copy never changes v. Unexported struct
+// fields are copied shallowly.
+func Clone[T any](v T) T {
+	c := deepCopy(reflect.ValueOf(&v).Elem())
+	return c.Interface().(T)
+}
+
+// deepCopy returns a deep copy of the value.
+func deepCopy(v reflect.Value) reflect.Value {
+	c := reflect.New(v.Type()).Elem()
+	switch v.Kind() {
+
+	case reflect.Ptr:
+		if v.IsNil() {
+			return c
+		}
+		p := reflect.New(v.Type().Elem())
+		p.Elem().Set(deepCopy(v.Elem()))
+		c.Set(p)
+
+	case reflect.Interface:
+		if v.IsNil() {
+			return c
+		}
+		c.Set(deepCopy(v.Elem()))
+
+	case reflect.Struct:
+		c.Set(v)
+		for i := 0; i < v.NumField(); i++ {
+			if f := c.Field(i); f.CanSet() {
+				f.Set(deepCopy(v.Field(i)))
+			}
+		}
+
+	case reflect.Slice:
+		if v.IsNil() {
+			return c
+		}
+		c.Set(reflect.MakeSlice(v.Type(), v.Len(), v.Len()))
+		for i := 0; i < v.Len(); i++ {
+			c.Index(i).Set(deepCopy(v.Index(i)))
+		}
+
+	case reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			c.Index(i).Set(deepCopy(v.Index(i)))
+		}
+
+	case reflect.Map:
+		if v.IsNil() {
+			return c
+		}
+		c.Set(reflect.MakeMapWithSize(v.Type(), v.Len()))
+		iter := v.MapRange()
+		for iter.Next() {
+			c.SetMapIndex(iter.Key(), deepCopy(iter.Value()))
+		}
+
+	default:
+		c.Set(v)
+	}
+	return c
+}
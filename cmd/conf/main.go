@@ -7,15 +7,15 @@
 package main
 
 import (
-	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
-	"os"
 
 	"fyne.io/fyne/v2"
 	"fyne.io/fyne/v2/app"
 	"fyne.io/fyne/v2/container"
 	"fyne.io/fyne/v2/dialog"
+	"github.com/teonet-go/conf"
 	"github.com/teonet-go/conf/fyne/form"
 	"github.com/teonet-go/conf/types"
 )
@@ -33,6 +33,7 @@ type Person struct {
 	FltArray []float64        `json:"float_array"`
 	Option   types.RadioGroup `json:"option"`
 	Message  types.Multiline  `json:"message"`
+	Level    types.Select     `json:"level" options:"debug,info,error"`
 }
 
 // main is the entry point of the program.
@@ -41,8 +42,9 @@ type Person struct {
 // data. It loads the JSON data from a file, decodes it into a data structure,
 // and adds the fields and values to the form. It creates a save button that
 // validates the form, updates the field values, encodes the modified data
-// structure back into JSON, and writes it to a file. Finally, it sets the
-// window content, resizes it, and shows the window.
+// structure back into JSON, and writes it to a file. If the file was modified
+// by someone else, it shows the conflict dialog. Finally, it sets the window
+// content, resizes it, and shows the window.
 func main() {
 	// Create a new Fyne application
 	a := app.New()
@@ -59,51 +61,67 @@ func main() {
 	person.Option.SetHorizontal()
 	//
 	person.Message.SetNumRows(4)
+	//
+	person.Level = "info"
+
+	// Config file which keeps 3 backups on save
+	file := conf.NewFile(filePath)
+	file.Backups = 3
 
 	// Load the JSON data from a file
-	err := loadJson(&person)
+	err := file.Load(&person)
 	if err != nil {
 		log.Println(err)
 	}
 
 	// Decode the JSON into map
 	// var data map[string]any
-	// err = loadJson(&data)
+	// err = file.Load(&data)
 	// if err != nil {
 	// 	log.Fatal(err)
 	// }
 	var data = person
 
-	// Create a form from the struct or map that contains JSON data
-	form := form.New(data)
-
-	// Create a save button
-	saveButton := form.NewSaveButton(&data,
-		// Save button callback
-		func() {
-			// Write the encoded JSON back to the file and show Info dialog or
-			// show error dialog at error.
-			if err := saveJson(data); err != nil {
-				dialog.ShowError(err, w)
-				return
-			}
-			dialog.ShowInformation(
-				"Success", "JSON file updated successfully!",
-				w,
-			)
-		},
-		// Form validation error callback
-		func(err error) {
-			msg := fmt.Sprintf("Cannot save this form:\n %s", err)
-			dialog.ShowError(fmt.Errorf(msg), w)
-		},
-	)
-
-	// Create a container for the form and save button
-	content := container.NewVBox(form, saveButton)
-
-	// Set the window content
-	w.SetContent(content)
+	// Show form with data in the window
+	var show func()
+	show = func() {
+		// Create a form from the struct or map that contains JSON data and
+		// show changes before saving
+		f := form.New(data)
+		f.SetConfirm(w)
+
+		// Create a save button
+		saveButton := f.NewSaveButton(&data,
+			// Save button callback
+			func() {
+				// Write the encoded JSON back to the file and show Info dialog
+				// or show error dialog at error.
+				err := file.Save(data)
+				var conflict *conf.ConflictError
+				switch {
+				case errors.As(err, &conflict):
+					showConflict(conflict, file, &data, show, w)
+				case err != nil:
+					dialog.ShowError(err, w)
+				default:
+					dialog.ShowInformation(
+						"Success", "JSON file updated successfully!",
+						w,
+					)
+				}
+			},
+			// Form validation error callback
+			func(err error) {
+				msg := fmt.Sprintf("Cannot save this form:\n %s", err)
+				dialog.ShowError(fmt.Errorf(msg), w)
+			},
+		)
+
+		// Create a container for the form and save button and set the window
+		// content
+		w.SetContent(container.NewVBox(f, saveButton))
+	}
+	show()
 
 	// Resize the window
 	w.Resize(fyne.NewSize(500, 500))
@@ -114,25 +132,35 @@ func main() {
 
 const filePath = "data.json"
 
-func loadJson(v any) error {
-	// Load the JSON data from a file
-	jsonData, err := os.ReadFile(filePath)
-	if err != nil {
-		return err
-	}
-
-	// Decode the JSON into a data structure
-	return json.Unmarshal(jsonData, v)
-}
-
-func saveJson(v any) error {
+// showConflict shows config file conflict dialog. The data is overwritten,
+// reloaded or merged depending on the user choice and the form is shown again
+// by the show function.
+func showConflict(conflict *conf.ConflictError, file *conf.File, data *Person,
+	show func(), w fyne.Window) {
 
-	// Encode the modified data structure back into JSON
-	data, err := json.MarshalIndent(v, "", "  ")
-	if err != nil {
-		return err
-	}
-
-	// Write the encoded JSON back to the file
-	return os.WriteFile(filePath, data, 0644)
+	form.ShowConflict(conflict,
+		// Overwrite
+		func() {
+			if err := file.Overwrite(*data); err != nil {
+				dialog.ShowError(err, w)
+			}
+		},
+		// Reload
+		func() {
+			if err := file.Load(data); err != nil {
+				dialog.ShowError(err, w)
+				return
+			}
+			show()
+		},
+		// Merge
+		func() {
+			if err := file.Merge(data); err != nil {
+				dialog.ShowError(err, w)
+				return
+			}
+			show()
+		},
+		w,
+	)
 }
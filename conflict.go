@@ -0,0 +1,210 @@
+// Copyright 2024 Kirill Scherba <kirill@scherba.ru>. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Config helper go package. Conflict module detects external modifications of
+// config files and merges them.
+
+package conf
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"reflect"
+	"sort"
+	"time"
+)
+
+// ErrConflict is the error wrapped by ConflictError. It can be checked with
+// errors.Is.
+var ErrConflict = errors.New("config file was modified externally")
+
+// ConflictError is returned by File.Save when the config file was modified by
+// someone else after it was loaded or saved.
+type ConflictError struct {
+	Path    string          // Config file path
+	ModTime time.Time       // Modification time of the modified file
+	Fields  []FieldConflict // Fields modified in the file
+}
+
+// FieldConflict describes a top-level field modified in the config file.
+type FieldConflict struct {
+	Name   string // Field name
+	Base   string // Field value when the file was loaded or saved
+	Theirs string // Field value in the modified file
+	Ours   string // Field value being saved
+}
+
+// Error returns the error message.
+func (e *ConflictError) Error() string {
+	return fmt.Sprintf("%s: %s, %d fields changed", e.Path, ErrConflict,
+		len(e.Fields))
+}
+
+// Unwrap returns ErrConflict.
+func (e *ConflictError) Unwrap() error { return ErrConflict }
+
+// Conflict returns true if the field was changed both in the file and in the
+// saved value to different values.
+func (c FieldConflict) Conflict() bool {
+	return c.Ours != c.Base && c.Ours != c.Theirs
+}
+
+// Merge merges external modifications of the config file to the value pointed
+// to by v. Fields modified in the file and not changed in v since the file was
+// loaded or saved get the file values, fields changed in v keep their values.
+// After Merge the next Save writes the file without conflict.
+func (f *File) Merge(v any) error {
+	return f.MergeContext(context.Background(), v)
+}
+
+// MergeContext is like Merge but waits for the file lock until the context is
+// done.
+func (f *File) MergeContext(ctx context.Context, v any) (err error) {
+	defer func() { trace("merge config", "path", f.Path, "error", err) }()
+
+	l, err := f.lock(ctx, true)
+	if err != nil {
+		return
+	}
+	defer l.Unlock()
+
+	disk, stat, err := f.read()
+	if err != nil {
+		return
+	}
+	base, baseOk := documentMap(f.doc)
+	theirs, theirsOk := documentMap(disk)
+	if !baseOk || !theirsOk {
+		if err = disk.Decode(v); err != nil {
+			return
+		}
+		f.doc, f.stat = disk, stat
+		return
+	}
+
+	ours, err := valueMap(v)
+	if err != nil {
+		return
+	}
+	for _, key := range mapKeys(base, theirs) {
+		if !reflect.DeepEqual(ours[key], base[key]) {
+			continue
+		}
+		if val, ok := theirs[key]; ok {
+			ours[key] = val
+		} else {
+			delete(ours, key)
+		}
+	}
+
+	// Decode merged values to v
+	data, err := json.Marshal(ours)
+	if err != nil {
+		return
+	}
+	if m, ok := v.(*map[string]any); ok {
+		clear(*m)
+	}
+	if err = json.Unmarshal(data, v); err != nil {
+		return
+	}
+	f.doc, f.stat = disk, stat
+	return
+}
+
+// checkConflict checks if the config file was modified after it was loaded or
+// saved by this File and returns *ConflictError if so. Removed file is not
+// treated as modified.
+func (f *File) checkConflict(v any) (err error) {
+	if f.doc == nil {
+		return
+	}
+	data, err := os.ReadFile(f.Path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	if err != nil || sha256.Sum256(data) == f.stat.sum {
+		return
+	}
+
+	e := &ConflictError{Path: f.Path}
+	if fi, err := os.Stat(f.Path); err == nil {
+		e.ModTime = fi.ModTime()
+	}
+
+	// Make field conflicts list, the list is empty if the modified file can't
+	// be parsed
+	disk, err := ParseJSONC(data)
+	if err != nil {
+		return e
+	}
+	base, _ := documentMap(f.doc)
+	theirs, _ := documentMap(disk)
+	ours, err := valueMap(v)
+	if err != nil {
+		return
+	}
+	for _, key := range mapKeys(base, theirs) {
+		if reflect.DeepEqual(base[key], theirs[key]) {
+			continue
+		}
+		e.Fields = append(e.Fields, FieldConflict{
+			Name:   key,
+			Base:   mapValueStr(base, key),
+			Theirs: mapValueStr(theirs, key),
+			Ours:   mapValueStr(ours, key),
+		})
+	}
+	return e
+}
+
+// documentMap returns document value as a map and true if the document root
+// is an object.
+func documentMap(doc *Document) (m map[string]any, ok bool) {
+	if doc == nil {
+		return
+	}
+	m, ok = doc.Value().(map[string]any)
+	return
+}
+
+// valueMap returns v encoded to JSON and decoded to a map.
+func valueMap(v any) (m map[string]any, err error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return
+	}
+	err = json.Unmarshal(data, &m)
+	return
+}
+
+// mapKeys returns sorted keys of all maps.
+func mapKeys(maps ...map[string]any) (keys []string) {
+	exists := make(map[string]bool)
+	for _, m := range maps {
+		for key := range m {
+			if !exists[key] {
+				exists[key] = true
+				keys = append(keys, key)
+			}
+		}
+	}
+	sort.Strings(keys)
+	return
+}
+
+// mapValueStr returns the map value by key as string or empty string if the
+// key does not exist.
+func mapValueStr(m map[string]any, key string) string {
+	val, ok := m[key]
+	if !ok {
+		return ""
+	}
+	return fmt.Sprintf("%v", val)
+}
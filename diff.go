@@ -0,0 +1,182 @@
+// Copyright 2024 Kirill Scherba <kirill@scherba.ru>. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Config helper go package. Diff module finds differences between two
+// configurations.
+
+package conf
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// DiffKind is a kind of the configuration difference.
+type DiffKind int
+
+// Configuration difference kinds.
+const (
+	DiffAdded DiffKind = iota
+	DiffRemoved
+	DiffModified
+)
+
+// String returns the name of the difference kind.
+func (k DiffKind) String() string {
+	switch k {
+	case DiffAdded:
+		return "added"
+	case DiffRemoved:
+		return "removed"
+	case DiffModified:
+		return "modified"
+	}
+	return "unknown"
+}
+
+// MarshalText encodes the difference kind as its name.
+func (k DiffKind) MarshalText() ([]byte, error) {
+	return []byte(k.String()), nil
+}
+
+// Difference is a field difference between two configurations.
+type Difference struct {
+	Kind DiffKind `json:"kind"`          // Difference kind
+	Path string   `json:"path"`          // Field path, JSON Pointer
+	Old  string   `json:"old,omitempty"` // Old field value as string or Redacted
+	New  string   `json:"new,omitempty"` // New field value as string or Redacted
+
+	OldValue any `json:"-"` // Old field value
+	NewValue any `json:"-"` // New field value
+}
+
+// Differences is a list of differences between two configurations.
+type Differences []Difference
+
+// Diff returns differences between a and b configurations. The a and b are
+// structs, map[string]any or pointers to them. Fields are compared by name,
+// nested structs and maps are compared field by field.
+//
+// The field paths are JSON Pointers (RFC 6901) made of field names, e.g.
+// "/Database/Pool". Old and New strings of values implementing Secret, or
+// containing them, are Redacted, OldValue and NewValue keep the values.
+func Diff(a, b any) (d Differences) {
+	diff(&d, "", a, b)
+	return
+}
+
+// String returns human-readable differences, one line per difference. Added
+// fields are printed as "+ /path: new", removed fields as "- /path: old" and
+// modified fields as "~ /path: old -> new".
+func (d Differences) String() string {
+	var b strings.Builder
+	for _, diff := range d {
+		switch diff.Kind {
+		case DiffAdded:
+			fmt.Fprintf(&b, "+ %s: %s\n", diff.Path, diff.New)
+		case DiffRemoved:
+			fmt.Fprintf(&b, "- %s: %s\n", diff.Path, diff.Old)
+		case DiffModified:
+			fmt.Fprintf(&b, "~ %s: %s -> %s\n", diff.Path, diff.Old, diff.New)
+		}
+	}
+	return b.String()
+}
+
+// JSON returns differences encoded to indented JSON.
+func (d Differences) JSON() ([]byte, error) {
+	if d == nil {
+		d = Differences{}
+	}
+	return json.MarshalIndent(d, "", "  ")
+}
+
+// diff appends differences between a and b to d.
+func diff(d *Differences, path string, a, b any) {
+	fa, fb := diffFields(a), diffFields(b)
+
+	// Removed and modified fields
+	for _, field := range fa.list {
+		p := path + "/" + escapePathSegment(field.Name)
+		other, ok := fb.byName[field.Name]
+		switch {
+		case !ok:
+			*d = append(*d, Difference{Kind: DiffRemoved, Path: p,
+				Old: diffString(field), OldValue: field.Value})
+		case reflect.DeepEqual(field.Value, other.Value):
+		case isDiffContainer(field.Value) && isDiffContainer(other.Value) &&
+			reflect.TypeOf(field.Value) == reflect.TypeOf(other.Value):
+			diff(d, p, field.Value, other.Value)
+		default:
+			*d = append(*d, Difference{Kind: DiffModified, Path: p,
+				Old: diffString(field), New: diffString(other),
+				OldValue: field.Value, NewValue: other.Value})
+		}
+	}
+
+	// Added fields
+	for _, field := range fb.list {
+		if _, ok := fa.byName[field.Name]; ok {
+			continue
+		}
+		*d = append(*d, Difference{Kind: DiffAdded,
+			Path: path + "/" + escapePathSegment(field.Name),
+			New:  diffString(field), NewValue: field.Value})
+	}
+}
+
+// diffString returns the field value as string, or Redacted if the value is
+// secret or contains secret values, so they are not shown or serialized.
+func diffString(field *Field[any]) string {
+	return redact(field.Value, field.ValueStr).(string)
+}
+
+// fieldSet is a list of fields with index by name.
+type fieldSet struct {
+	list   Fields[any]
+	byName map[string]*Field[any]
+}
+
+// diffFields returns fields of the struct or the map o. Pointers are
+// dereferenced, nil pointers have no fields.
+func diffFields(o any) (fs fieldSet) {
+	fs.byName = make(map[string]*Field[any])
+	v := reflect.ValueOf(o)
+	for v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return
+		}
+		v = v.Elem()
+	}
+	if !v.IsValid() || !isDiffContainer(v.Interface()) {
+		return
+	}
+	fs.list = GetFields(v.Interface(), func(*Field[any]) {})
+	if isMap(v.Interface()) {
+		sort.Slice(fs.list, func(i, j int) bool {
+			return fs.list[i].Name < fs.list[j].Name
+		})
+	}
+	for _, field := range fs.list {
+		fs.byName[field.Name] = field
+	}
+	return
+}
+
+// isDiffContainer checks if the value is compared field by field. Values
+// implementing ValueGetter are compared as a whole.
+func isDiffContainer(o any) bool {
+	if _, ok := o.(ValueGetter); ok {
+		return false
+	}
+	return o != nil && (isStruct(o) || isMap(o))
+}
+
+// escapePathSegment escapes JSON Pointer path segment.
+func escapePathSegment(s string) string {
+	return strings.NewReplacer("~", "~0", "/", "~1").Replace(s)
+}
@@ -0,0 +1,114 @@
+package conf
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestDiff(t *testing.T) {
+
+	type pool struct {
+		Min int
+		Max int
+	}
+	type config struct {
+		Name string
+		Pool pool
+		Tags []string
+	}
+
+	a := config{"db", pool{1, 10}, []string{"a"}}
+	b := a
+	b.Pool.Max = 20
+	b.Tags = []string{"a", "b"}
+
+	d := Diff(a, &b)
+	if len(d) != 2 {
+		t.Fatalf("wrong differences:\n%s", d)
+	}
+	if d[0].Kind != DiffModified || d[0].Path != "/Pool/Max" ||
+		d[0].Old != "10" || d[0].New != "20" {
+		t.Fatalf("wrong pool difference: %+v", d[0])
+	}
+
+	const want = "~ /Pool/Max: 10 -> 20\n~ /Tags: [a] -> [a b]\n"
+	if s := d.String(); s != want {
+		t.Fatalf("wrong string:\n%s", s)
+	}
+
+	// Maps
+	m1 := map[string]any{"name": "a", "a/b": 1.0}
+	m2 := map[string]any{"name": "a", "port": 80.0}
+	d = Diff(m1, m2)
+	if len(d) != 2 || d[0].Kind != DiffRemoved || d[0].Path != "/a~1b" ||
+		d[1].Kind != DiffAdded || d[1].Path != "/port" {
+		t.Fatalf("wrong map differences:\n%s", d)
+	}
+
+	data, err := d.JSON()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var decoded []map[string]string
+	if err = json.Unmarshal(data, &decoded); err != nil {
+		t.Fatal(err)
+	}
+	if decoded[1]["kind"] != "added" || decoded[1]["new"] != "80" {
+		t.Fatalf("wrong JSON:\n%s", data)
+	}
+}
+
+type diffRange struct{ Min, Max, Value int }
+
+func (r diffRange) GetValue() string { return NumberToString(r.Value) }
+
+func TestDiffValueGetter(t *testing.T) {
+
+	type config struct{ Volume diffRange }
+	a, b := config{diffRange{0, 100, 0}}, config{diffRange{0, 100, 50}}
+
+	// Special field types are compared and shown by GetValue
+	d := Diff(a, b)
+	if len(d) != 1 || d[0].Path != "/Volume" || d[0].Old != "0" ||
+		d[0].New != "50" {
+		t.Fatalf("wrong differences:\n%s", d)
+	}
+	if fields := GetFields(b, func(*Field[any]) {}); fields[0].ValueStr != "50" {
+		t.Fatalf("wrong ValueStr %q", fields[0].ValueStr)
+	}
+}
+
+type diffPassword string
+
+func (diffPassword) IsSecret() bool { return true }
+
+func TestDiffSecret(t *testing.T) {
+
+	type db struct {
+		User     string
+		Password diffPassword
+	}
+	a := map[string]any{"db": db{"admin", "pw1"}, "token": diffPassword("tk")}
+	b := map[string]any{"db": db{"root", "pw2"}, "token": []int{1}}
+
+	d := Diff(a, b)
+	data, err := d.JSON()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, s := range []string{d.String(), string(data)} {
+		if strings.Contains(s, "pw1") || strings.Contains(s, "pw2") ||
+			strings.Contains(s, "tk") || !strings.Contains(s, "root") {
+			t.Fatalf("secret values should be redacted:\n%s", s)
+		}
+	}
+	if p := d.Patch(); len(p) != 3 {
+		t.Fatalf("wrong patch %v", p)
+	}
+	for _, diff := range d {
+		if diff.Path == "/db/Password" && diff.NewValue != diffPassword("pw2") {
+			t.Fatalf("new value should be kept, got %v", diff.NewValue)
+		}
+	}
+}
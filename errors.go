@@ -0,0 +1,25 @@
+// Copyright 2024 Kirill Scherba <kirill@scherba.ru>. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Config helper go package. Errors module defines errors of the fields API.
+
+package conf
+
+import "errors"
+
+// Errors returned by GetFieldsE and Field.SetValue. They are wrapped with
+// details and can be checked with errors.Is.
+var (
+	// ErrUnsupportedType is returned when the object type can't be used to
+	// get or set fields.
+	ErrUnsupportedType = errors.New("unsupported type")
+
+	// ErrNotAddressable is returned when the field value can't be set, e.g.
+	// when a struct is passed by value instead of by pointer.
+	ErrNotAddressable = errors.New("value is not addressable")
+
+	// ErrUnknownField is returned when the object has no field with the
+	// field name.
+	ErrUnknownField = errors.New("unknown field")
+)
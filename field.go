@@ -10,6 +10,7 @@ import (
 	"reflect"
 	"strconv"
 	"strings"
+	"sync"
 )
 
 // Field is a struct that contains metadata and values for a single field of a
@@ -24,6 +25,9 @@ type Field[T any] struct {
 	ValueStr    string // Field value as string
 	Value       any    // Field with real struct value
 
+	// Struct field tag, empty for map fields
+	Tag reflect.StructTag
+
 	// Field entry is a custom field which can be used in GetFields and
 	// SetValues callbacks
 	Entry T
@@ -47,20 +51,73 @@ type Field[T any] struct {
 //
 // If the value cannot be converted to the field's type, an error is returned.
 //
-// If the parameter 'p' is not a pointer to a struct or a map, the function
-// panics.
+// If the parameter 'p' is a pointer to JSONC Document, the field value is
+// replaced in the document text and all other document text is preserved.
+//
+// If the parameter 'p' is a pointer to a top-level array or a scalar decoded
+// by GetFieldsE, the array element or the scalar value is set.
+//
+// If the parameter 'p' type is not supported or 'p' is a nil pointer or map,
+// the function returns an error wrapping ErrUnsupportedType. A struct passed
+// by value returns an error wrapping ErrNotAddressable, and an unknown struct
+// field or array index returns an error wrapping ErrUnknownField.
 //
 // The function does not modify the 'p' parameter directly, but it modifies the
 // value of the specified field.
 //
-// The function does not have any return values.
+// Subscribers of the field added with Subscribe are notified when the field
+// value is changed.
 func (field *Field[T]) SetValue(p any, value ...string) (err error) {
 
+	// Check nil value
+	if p == nil {
+		return fmt.Errorf("%w: nil value", ErrUnsupportedType)
+	}
+
+	// Check typed nil value: nil pointer, nil map or pointer to nil map
+	v := reflect.ValueOf(p)
+	if v.Kind() == reflect.Ptr && !v.IsNil() && v.Elem().Kind() == reflect.Map {
+		v = v.Elem()
+	}
+	if (v.Kind() == reflect.Ptr || v.Kind() == reflect.Map) && v.IsNil() {
+		return fmt.Errorf("%w: nil %T", ErrUnsupportedType, p)
+	}
+
+	// Trace set value, secret values are redacted
+	defer func() {
+		if !tracing() {
+			return
+		}
+		var val any = field.Value
+		if len(value) > 0 {
+			val = value[0]
+		}
+		trace("set field", "name", field.Name, "type", field.Type,
+			"value", redact(field.Value, val), "error", err)
+	}()
+
+	// Get old field value and notify subscribers if the value was changed
+	if subscribed(p) {
+		old := fieldValue(p, field.Name)
+		defer func() {
+			if err != nil {
+				return
+			}
+			if val := fieldValue(p, field.Name); !reflect.DeepEqual(old, val) {
+				notify(p, Change{field.Name, old, val})
+			}
+		}()
+	}
+
 	// Check if the p parameter is a pointer to a struct or a map, set values
-	// for struct or map or panic if parameter is not valid (is not a pointer
-	//to a struct or a map).
+	// for struct or map or return error if parameter is not valid (is not a
+	// pointer to a struct or a map).
 	switch {
 
+	// If the p parameter is JSONC document than patch its value in place
+	case isDocument(p):
+		err = field.setDocumentValue(p.(*Document), value...)
+
 	// If the p parameter is a pointer to a struct than set struct values
 	case isStructPtr(p):
 		err = field.setStructValue(p, value...)
@@ -75,9 +132,19 @@ func (field *Field[T]) SetValue(p any, value ...string) (err error) {
 		}
 		err = field.setMapValue(m, value...)
 
-	// If the p parameter is not a pointer to a struct or a map, panic
+	// If the p parameter is a struct passed by value return error
+	case isStruct(p):
+		err = fmt.Errorf("%w: %T should be passed by pointer",
+			ErrNotAddressable, p)
+
+	// If the p parameter is a pointer to an array or a scalar than set its
+	// element or value
+	case reflect.TypeOf(p).Kind() == reflect.Ptr:
+		err = field.setElemValue(reflect.ValueOf(p).Elem(), value...)
+
+	// If the p parameter is not supported return error
 	default:
-		panic("p parameter of SetValue should be a pointer to struct or a map")
+		err = fmt.Errorf("%w: %T", ErrUnsupportedType, p)
 	}
 
 	return
@@ -119,55 +186,42 @@ func (field *Field[T]) setStructValue(p any, values ...string) (err error) {
 
 	v := reflect.ValueOf(p).Elem() // Struct value
 	name := field.Name             // Struct field name
-	val := v.FieldByName(name)     // Struct field value
+
+	// Get struct field value and converter from the cached type plan, fields
+	// promoted from embedded structs are found by name
+	var val reflect.Value
+	var convert converter
+	if f, ok := planOf(v.Type()).field(name); ok {
+		val, convert = v.Field(f.index), f.convert
+	} else {
+		val = v.FieldByName(name)
+	}
+
+	// Check the field exists and can be set
+	if !val.IsValid() {
+		return fmt.Errorf("%w: %s", ErrUnknownField, name)
+	}
+	if !val.CanSet() {
+		return fmt.Errorf("%w: field %s", ErrNotAddressable, name)
+	}
 
 	// Set object p field value from real value
 	var value string
 	if len(values) == 0 {
-		val.Set(reflect.ValueOf(field.Value))
-		return
+		return setReal(val, name, field.Value)
 	}
 	value = values[0]
 
 	// Set object p field value from string value
-	if !(val.IsValid() && val.CanSet()) {
-		err = setError(name, value, val.Type().String())
-		return
+	if convert == nil {
+		convert = converterOf(val.Type())
 	}
-	switch val.Type().String() {
-	case "string":
-		val.SetString(value)
-	case "int", "int8", "int16", "int32", "int64", "uint", "uint8",
-		"uint16", "uint32", "uint64":
-		i, _ := strconv.Atoi(value)
-		val.SetInt(int64(i))
-	case "float32":
-		f, _ := strconv.ParseFloat(value, 32)
-		val.SetFloat(f)
-	case "float64":
-		f, _ := strconv.ParseFloat(value, 64)
-		val.SetFloat(f)
-	case "bool":
-		val.SetBool(value == "true")
-	case "[]int":
-		s := strings.Split(strings.Trim(value, "[]"), " ")
-		a := make([]int, len(s))
-		for i, str := range s {
-			val, _ := strconv.Atoi(str)
-			a[i] = val
-		}
-		val.Set(reflect.ValueOf(a))
-	case "[]float64":
-		s := strings.Split(strings.Trim(value, "[]"), " ")
-		a := make([]float64, len(s))
-		for i, str := range s {
-			val, _ := strconv.ParseFloat(str, 64)
-			a[i] = val
-		}
-		val.Set(reflect.ValueOf(a))
-	default:
+	parsed, err := convert(value)
+	if err != nil {
 		err = setError(name, value, val.Type().String())
+		return
 	}
+	val.Set(parsed)
 
 	return
 }
@@ -179,48 +233,231 @@ func (field *Field[T]) setMapValue(m map[string]any, values ...string) (err erro
 	// Set map m field value from real value
 	var value string
 	if len(values) == 0 {
-		m[key] = reflect.ValueOf(field.Value)
+		m[key] = field.Value
 		return
 	}
 	value = values[0]
 
-	// Set map m field value from string value
-	switch field.Type {
-	case "string":
-		m[key] = value
-	case "int", "int8", "int16", "int32", "int64", "uint", "uint8",
-		"uint16", "uint32", "uint64":
-		i, _ := strconv.Atoi(value)
-		m[key] = int64(i)
-	case "float32":
-		f, _ := strconv.ParseFloat(value, 32)
-		m[key] = f
-	case "float64":
-		f, _ := strconv.ParseFloat(value, 64)
-		m[key] = f
-	case "bool":
-		m[key] = value == "true"
-	case "[]interface {}":
-		s := strings.Split(strings.Trim(value, "[]"), " ")
-		a := make([]any, len(s))
-		for i, str := range s {
-			val, err := strconv.Atoi(str)
-			if err != nil {
-				val, _ := strconv.ParseFloat(str, 64)
-				a[i] = val
-				continue
-			}
-			a[i] = val
+	// Set map m field value from string value converted to the map type of
+	// the field type
+	t, ok := mapTypes[field.Type]
+	if !ok {
+		return fmt.Errorf("%w: %w", ErrUnsupportedType,
+			setError(field.Name, value, field.Type))
+	}
+	v, err := parseValue(t, value)
+	if err != nil {
+		return setError(field.Name, value, field.Type)
+	}
+	m[key] = v.Interface()
+	return
+}
+
+// mapTypes are the types of map values by field type. Integers are stored in
+// maps as int64 and floats as float64, nil values and slices are stored as
+// numbers or strings.
+var mapTypes = map[string]reflect.Type{
+	"string":         reflect.TypeOf(""),
+	"int":            reflect.TypeOf(int64(0)),
+	"int8":           reflect.TypeOf(int64(0)),
+	"int16":          reflect.TypeOf(int64(0)),
+	"int32":          reflect.TypeOf(int64(0)),
+	"int64":          reflect.TypeOf(int64(0)),
+	"uint":           reflect.TypeOf(int64(0)),
+	"uint8":          reflect.TypeOf(int64(0)),
+	"uint16":         reflect.TypeOf(int64(0)),
+	"uint32":         reflect.TypeOf(int64(0)),
+	"uint64":         reflect.TypeOf(int64(0)),
+	"float32":        reflect.TypeOf(float64(0)),
+	"float64":        reflect.TypeOf(float64(0)),
+	"bool":           reflect.TypeOf(false),
+	"[]interface {}": reflect.TypeOf([]any(nil)),
+	"interface {}":   reflect.TypeOf((*any)(nil)).Elem(),
+}
+
+// setElemValue sets the top-level array element or the scalar value v from
+// string value. Array elements are found by field name, the scalar field name
+// is empty.
+func (field *Field[T]) setElemValue(v reflect.Value, values ...string) (
+	err error) {
+
+	// Use array or map in the interface value
+	if v.Kind() == reflect.Interface && !v.IsNil() {
+		switch e := v.Elem(); {
+		case isMap(e.Interface()):
+			return field.setMapValue(e.Interface().(map[string]any), values...)
+		case e.Kind() == reflect.Slice:
+			v = e
 		}
-		m[key] = a
-	default:
-		err = setError(field.Name, value, field.Type)
 	}
+
+	// Get array element
+	switch {
+	case v.Kind() == reflect.Slice || v.Kind() == reflect.Array:
+		i, err := sliceIndex(v, field.Name, false)
+		if err != nil {
+			return fmt.Errorf("%w: %s: %w", ErrUnknownField, field.Name, err)
+		}
+		v = v.Index(i)
+	case field.Name != "":
+		return fmt.Errorf("%w: %s", ErrUnknownField, field.Name)
+	case !isScalar(v.Kind()) && v.Kind() != reflect.Interface:
+		return fmt.Errorf("%w: %s", ErrUnsupportedType, v.Type())
+	}
+	if !v.CanSet() {
+		return fmt.Errorf("%w: %s", ErrNotAddressable, field.Name)
+	}
+
+	// Set value from real value
+	if len(values) == 0 {
+		return setReal(v, field.Name, field.Value)
+	}
+
+	// Set interface value the same way as map value
+	if v.Kind() == reflect.Interface {
+		m := make(map[string]any)
+		if err = field.setMapValue(m, values...); err != nil {
+			return
+		}
+		return setReal(v, field.Name, m[field.Name])
+	}
+
+	// Set value from string value
+	parsed, err := parseValue(v.Type(), values[0])
+	if err != nil {
+		return setError(field.Name, values[0], v.Type().String())
+	}
+	v.Set(parsed)
 	return
 }
 
+// setReal sets the value v from real value. The nil value sets zero value.
+func setReal(v reflect.Value, name string, value any) error {
+	val := reflect.ValueOf(value)
+	switch {
+	case !val.IsValid():
+		val = reflect.Zero(v.Type())
+	case !val.Type().AssignableTo(v.Type()):
+		return setError(name, value, v.Type().String())
+	}
+	v.Set(val)
+	return nil
+}
+
+// setDocumentValue sets the JSONC document member value from string value.
+func (field *Field[T]) setDocumentValue(d *Document, values ...string) (err error) {
+
+	// Set document member value from real value
+	if len(values) == 0 {
+		return d.Set(field.Name, field.Value)
+	}
+
+	// Convert string value the same way as for map and set document member
+	m := make(map[string]any)
+	if err = field.setMapValue(m, values...); err != nil {
+		return
+	}
+	return d.Set(field.Name, m[field.Name])
+}
+
+// parseValue converts the string value to the value of type t. Strings,
+// numbers, bools and slices of them are supported. Slices are written as
+// space separated elements in square brackets, the way fmt prints them.
+func parseValue(t reflect.Type, s string) (reflect.Value, error) {
+	return converterOf(t)(s)
+}
+
+// converter converts the string value to the value of its type.
+type converter func(s string) (reflect.Value, error)
+
+// converters caches converters by type.
+var converters sync.Map // map[reflect.Type]converter
+
+// converterOf returns the cached converter of string values to the values of
+// type t.
+func converterOf(t reflect.Type) converter {
+	if c, ok := converters.Load(t); ok {
+		return c.(converter)
+	}
+	c, _ := converters.LoadOrStore(t, newConverter(t))
+	return c.(converter)
+}
+
+// newConverter returns the converter of string values to the values of type t
+// used by parseValue. The type kind is checked once when the converter is
+// created, so the converter is cached and reused for the type.
+func newConverter(t reflect.Type) converter {
+	switch t.Kind() {
+	case reflect.String:
+		return func(s string) (v reflect.Value, err error) {
+			v = reflect.New(t).Elem()
+			v.SetString(s)
+			return
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return func(s string) (v reflect.Value, err error) {
+			v = reflect.New(t).Elem()
+			i, err := strconv.ParseInt(s, 10, t.Bits())
+			v.SetInt(i)
+			return
+		}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32,
+		reflect.Uint64:
+		return func(s string) (v reflect.Value, err error) {
+			v = reflect.New(t).Elem()
+			u, err := strconv.ParseUint(s, 10, t.Bits())
+			v.SetUint(u)
+			return
+		}
+	case reflect.Float32, reflect.Float64:
+		return func(s string) (v reflect.Value, err error) {
+			v = reflect.New(t).Elem()
+			f, err := strconv.ParseFloat(s, t.Bits())
+			v.SetFloat(f)
+			return
+		}
+	case reflect.Bool:
+		return func(s string) (v reflect.Value, err error) {
+			v = reflect.New(t).Elem()
+			b, err := strconv.ParseBool(s)
+			v.SetBool(b)
+			return
+		}
+	case reflect.Interface:
+		// Number or string the same way as in setMapValue
+		return func(s string) (v reflect.Value, err error) {
+			v = reflect.New(t).Elem()
+			if i, e := strconv.Atoi(s); e == nil {
+				v.Set(reflect.ValueOf(i))
+			} else if f, e := strconv.ParseFloat(s, 64); e == nil {
+				v.Set(reflect.ValueOf(f))
+			} else {
+				v.Set(reflect.ValueOf(s))
+			}
+			return
+		}
+	case reflect.Slice:
+		return func(s string) (v reflect.Value, err error) {
+			elem := converterOf(t.Elem())
+			elems := strings.Fields(strings.Trim(s, "[]"))
+			v = reflect.MakeSlice(t, len(elems), len(elems))
+			for i, s := range elems {
+				var e reflect.Value
+				if e, err = elem(s); err != nil {
+					return
+				}
+				v.Index(i).Set(e)
+			}
+			return
+		}
+	}
+	return func(s string) (reflect.Value, error) {
+		return reflect.New(t).Elem(), fmt.Errorf("unsupported type %s", t)
+	}
+}
+
 // setError returns an error with the provided field name, value, and type.
-func setError(name, value, t string) error {
+func setError(name string, value any, t string) error {
 	return fmt.Errorf("can't set %s: %v of type %s", name, value, t)
 }
 
@@ -1,8 +1,10 @@
 package conf
 
 import (
+	"errors"
 	"fmt"
 	"reflect"
+	"strconv"
 	"unicode"
 )
 
@@ -15,65 +17,158 @@ type Fields[T any] []*Field[T]
 //
 // The function accepts two parameters:
 //
-//   - o: the object from which to extract the fields. It may be a struct or a
-//     map[string]any.
+//   - o: the object from which to extract the fields. It may be a struct, a
+//     map[string]any, a pointer to them or a pointer to JSONC Document.
 //   - f: the function to be called for each field, which takes a pointer to a
 //     Field[T] struct as its parameter. Where T is the type of the Entry fied
 //     in the Field struct.
 //
 // It returns a Fields[T] which is a slice of pointers to Field[T] structs.
+//
+// GetFields panics if the o parameter type is not supported. Use GetFieldsE to
+// get an error instead.
 func GetFields[T any](o any, f func(field *Field[T])) (fields Fields[T]) {
+	fields, err := GetFieldsE(o, f)
+	if err != nil {
+		panic(err)
+	}
+	return
+}
+
+// GetFieldsE is like GetFields but returns an error wrapping
+// ErrUnsupportedType instead of panic if the o parameter type is not
+// supported.
+//
+// In addition to GetFields objects it supports top-level arrays and scalars
+// decoded from JSON to any value. Array elements are fields named by element
+// index, e.g. "0", and a scalar is a single field with empty name. Such fields
+// are set with Field.SetValue to a pointer to the array or the scalar.
+func GetFieldsE[T any](o any, f func(field *Field[T])) (fields Fields[T],
+	err error) {
 
 	// Make description of fields
-	makeField := func(fld reflect.Value, name, nameDisplay string) {
-		fieldValue := fld.Interface()
-		fieldType := fld.Type()
-		field := &Field[T]{
-			Name:        name,
-			Value:       fieldValue,
-			NameDisplay: nameDisplay,
-			Type:        fieldType.String(),
-			ValueStr:    fmt.Sprintf("%v", fieldValue),
+	makeField := func(fld reflect.Value, name, nameDisplay string,
+		plan *fieldPlan) {
+
+		field := &Field[T]{Name: name, NameDisplay: nameDisplay}
+		switch {
+		case plan != nil:
+			field.Value = fld.Interface()
+			field.Type, field.Tag = plan.typeStr, plan.tag
+			field.ValueStr = valueString(field.Value)
+		case fld.IsValid():
+			field.Value = fld.Interface()
+			field.Type = fld.Type().String()
+			field.ValueStr = valueString(field.Value)
+		default:
+			// Nil value of map or array, e.g. null in JSON
+			field.Type = "interface {}"
 		}
 		fields = append(fields, field)
 		f(field)
 
-		fmt.Printf("%s: %v of type %v\n", field.Name, field.Value, field.Type)
+		if tracing() {
+			trace("get field", "name", field.Name, "type", field.Type,
+				"value", redact(field.Value, field.ValueStr))
+		}
 	}
 
+	// Dereference pointers to structs, maps, arrays and scalars
+	v := reflect.ValueOf(o)
+	for v.Kind() == reflect.Ptr && !isDocument(v.Interface()) {
+		if v.IsNil() {
+			return nil, fmt.Errorf("%w: nil %T", ErrUnsupportedType, o)
+		}
+		v = v.Elem()
+	}
+	for v.Kind() == reflect.Interface && !v.IsNil() {
+		v = v.Elem()
+	}
+	if !v.IsValid() || (v.Kind() == reflect.Interface && v.IsNil()) {
+		return nil, fmt.Errorf("%w: nil value", ErrUnsupportedType)
+	}
+	o = v.Interface()
+
 	// Make fields
 	switch {
 
-	// If the o object is struct
+	// If the o object is struct than use its exported fields from the cached
+	// type plan
 	case isStruct(o):
-		v := reflect.ValueOf(o)
-		t := reflect.TypeOf(o)
-		for i := 0; i < v.NumField(); i++ {
-			field := v.Field(i)
-			fieldName := t.Field(i).Name
-			if field.CanInterface() {
-				makeField(field, fieldName, fieldName)
-			}
+		plan := planOf(v.Type())
+		for i := range plan.fields {
+			f := &plan.fields[i]
+			makeField(v.Field(f.index), f.name, f.name, f)
 		}
 
 	// If the o object is map
 	case isMap(o):
 		for key, val := range o.(map[string]interface{}) {
-			v := reflect.ValueOf(val)
-			t := reflect.TypeOf(val)
-			fmt.Println("key: ", key, v, t)
+			makeField(reflect.ValueOf(val), key, uppercaseFirstRune(key), nil)
+		}
 
-			makeField(v, key, uppercaseFirstRune(key))
+	// If the o object is JSONC document than use its root object members in
+	// the document order
+	case isDocument(o):
+		root := o.(*Document).Root()
+		if root.Kind != NodeObject {
+			return nil, fmt.Errorf("%w: document with %s root",
+				ErrUnsupportedType, root.Kind)
+		}
+		for _, member := range root.Children {
+			v := reflect.ValueOf(member.Value())
+			makeField(v, member.Key, uppercaseFirstRune(member.Key), nil)
+		}
+
+	// If the o object is top-level array than use its elements
+	case v.Kind() == reflect.Slice || v.Kind() == reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			e := v.Index(i)
+			if e.Kind() == reflect.Interface {
+				e = e.Elem()
+			}
+			makeField(e, strconv.Itoa(i), fmt.Sprintf("Item %d", i+1), nil)
 		}
 
-	// If the o parameter is not a struct or a map, panic
+	// If the o object is scalar than use it as the only field
+	case isScalar(v.Kind()):
+		makeField(v, "", "Value", nil)
+
+	// If the o parameter is not supported return error
 	default:
-		panic("o parameter of GetFields should be a struct or a map")
+		return nil, fmt.Errorf("%w: %T", ErrUnsupportedType, o)
 	}
 
 	return
 }
 
+// ValueGetter is implemented by special field types which values are shown
+// as strings, e.g. the types of the types package. Their Field.ValueStr is
+// made with GetValue and Diff compares them as a whole.
+type ValueGetter interface {
+	GetValue() string
+}
+
+// valueString returns the field value as string.
+func valueString(v any) string {
+	if g, ok := v.(ValueGetter); ok {
+		return g.GetValue()
+	}
+	return fmt.Sprintf("%v", v)
+}
+
+// isScalar checks if the kind is a string, a number or a bool.
+func isScalar(k reflect.Kind) bool {
+	switch k {
+	case reflect.String, reflect.Bool, reflect.Int, reflect.Int8,
+		reflect.Int16, reflect.Int32, reflect.Int64, reflect.Uint,
+		reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return true
+	}
+	return false
+}
+
 // SetValues iterates over each field in the Fields collection and sets their
 // values based on the provided function.
 //
@@ -81,14 +176,50 @@ func GetFields[T any](o any, f func(field *Field[T])) (fields Fields[T]) {
 //   - p: The target object where the field values will be set.
 //   - f: A function that takes a pointer to a Field and returns a string
 //     representing the field value.
-func (fields Fields[T]) SetValues(p any, f func(field *Field[T]) (string, bool)) {
-	for _, field := range fields {
-		if txt, isStr := f(field); isStr {
-			field.SetValue(p, txt)
-			continue
+//
+// The values are set transactionally: they are set to a copy of p which
+// replaces p only when all values are set successfully, so p is never
+// partially updated. Errors of all fields are joined to the returned error.
+// Subscribers of p are notified about changed fields.
+func (fields Fields[T]) SetValues(p any, f func(field *Field[T]) (string, bool)) error {
+	set := func(c any) error {
+		var errs []error
+		for _, field := range fields {
+			if txt, isStr := f(field); isStr {
+				errs = append(errs, field.SetValue(c, txt))
+				continue
+			}
+			errs = append(errs, field.SetValue(c))
 		}
-		field.SetValue(p)
+		return errors.Join(errs...)
 	}
+
+	// Set JSONC document values to the document copy
+	if isDocument(p) {
+		d := p.(*Document)
+		c, err := ParseJSONC(d.Bytes())
+		if err != nil {
+			return err
+		}
+		if err = set(c); err != nil {
+			return err
+		}
+		old, _ := documentMap(d)
+		*d = *c
+		if subscribed(p) {
+			cur, _ := documentMap(d)
+			notify(p, fieldChanges(old, cur)...)
+		}
+		return nil
+	}
+
+	// Set struct or map values to the value copy
+	return applyAtomic(p, func(v reflect.Value) (reflect.Value, error) {
+		if v.Kind() == reflect.Map {
+			return v, set(v.Interface())
+		}
+		return v, set(v.Addr().Interface())
+	})
 }
 
 // uppercaseFirstRune converts the first rune of a string to uppercase.
@@ -0,0 +1,145 @@
+package conf
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestSetValues(t *testing.T) {
+
+	type config struct {
+		Name string
+		Port int
+		Rate float64
+	}
+	c := config{"app", 80, 1.5}
+	values := map[string]string{"Name": "new", "Port": "http", "Rate": "x"}
+
+	fields := GetFields(c, func(*Field[any]) {})
+	set := func(field *Field[any]) (string, bool) {
+		return values[field.Name], true
+	}
+
+	// Invalid values are reported and nothing is changed
+	var changes int
+	unsubscribe := Subscribe(&c, "", func(Change) { changes++ })
+	defer unsubscribe()
+	if err := fields.SetValues(&c, set); err == nil {
+		t.Fatal("invalid values should be an error")
+	}
+	if c != (config{"app", 80, 1.5}) || changes != 0 {
+		t.Fatalf("failed SetValues should not change config: %+v", c)
+	}
+
+	// Valid values are set
+	values["Port"], values["Rate"] = "8080", "2.5"
+	if err := fields.SetValues(&c, set); err != nil {
+		t.Fatal(err)
+	}
+	if c != (config{"new", 8080, 2.5}) || changes != 3 {
+		t.Fatalf("wrong values %+v or changes %d", c, changes)
+	}
+
+	// Map values
+	m := map[string]any{"name": "app", "port": 80}
+	fields = GetFields(m, func(*Field[any]) {})
+	values = map[string]string{"name": "new", "port": "8080"}
+	if err := fields.SetValues(m, set); err != nil {
+		t.Fatal(err)
+	}
+	if m["name"] != "new" || m["port"] != int64(8080) {
+		t.Fatalf("wrong map values: %v", m)
+	}
+
+	// Invalid map values decoded from JSON are reported and nothing is
+	// changed
+	m = map[string]any{"name": "app", "port": 80.0, "on": true}
+	fields = GetFields(m, func(*Field[any]) {})
+	for _, values = range []map[string]string{
+		{"name": "new", "port": "abc", "on": "true"},
+		{"name": "new", "port": "8080", "on": "yes"},
+	} {
+		if err := fields.SetValues(&m, set); err == nil {
+			t.Fatalf("invalid values %v should be an error", values)
+		}
+		if m["name"] != "app" || m["port"] != 80.0 || m["on"] != true {
+			t.Fatalf("failed SetValues should not change map: %v", m)
+		}
+	}
+}
+
+func TestGetFieldsE(t *testing.T) {
+
+	// Unsupported types
+	for _, o := range []any{nil, func() {}, (*struct{})(nil)} {
+		if _, err := GetFieldsE(o, func(*Field[any]) {}); !errors.Is(err,
+			ErrUnsupportedType) {
+			t.Fatalf("%T: got error %v, want ErrUnsupportedType", o, err)
+		}
+	}
+
+	// Top-level array decoded from JSON
+	var a any
+	if err := json.Unmarshal([]byte(`[1, "two", null]`), &a); err != nil {
+		t.Fatal(err)
+	}
+	fields, err := GetFieldsE(a, func(*Field[any]) {})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(fields) != 3 || fields[1].Name != "1" || fields[1].Value != "two" ||
+		fields[2].Value != nil {
+		t.Fatalf("wrong array fields: %v", fields)
+	}
+	if err = fields[0].SetValue(&a, "5"); err != nil {
+		t.Fatal(err)
+	}
+	if err = fields[2].SetValue(&a, "x"); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(a, []any{5.0, "two", "x"}) {
+		t.Fatalf("wrong array: %v", a)
+	}
+
+	// Top-level scalar
+	var s any = "text"
+	fields, err = GetFieldsE(&s, func(*Field[any]) {})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(fields) != 1 || fields[0].Name != "" || fields[0].Value != "text" {
+		t.Fatalf("wrong scalar fields: %v", fields)
+	}
+	if err = fields[0].SetValue(&s, "new"); err != nil || s != "new" {
+		t.Fatalf("wrong scalar %v or error %v", s, err)
+	}
+}
+
+func TestSetValueErrors(t *testing.T) {
+
+	type config struct{ Name string }
+	var c config
+	field := &Field[any]{Name: "Name", Type: "string"}
+
+	for _, test := range []struct {
+		p   any
+		f   *Field[any]
+		err error
+	}{
+		{nil, field, ErrUnsupportedType},
+		{c, field, ErrNotAddressable},
+		{&c, &Field[any]{Name: "Unknown", Type: "string"}, ErrUnknownField},
+		{func() {}, field, ErrUnsupportedType},
+		{(*config)(nil), field, ErrUnsupportedType},
+		{(*map[string]any)(nil), field, ErrUnsupportedType},
+		{new(map[string]any), field, ErrUnsupportedType},
+		{map[string]any(nil), field, ErrUnsupportedType},
+		{(*Document)(nil), field, ErrUnsupportedType},
+	} {
+		if err := test.f.SetValue(test.p, "x"); !errors.Is(err, test.err) {
+			t.Fatalf("%T: got error %v, want %v", test.p, err, test.err)
+		}
+	}
+}
@@ -0,0 +1,396 @@
+// Copyright 2024 Kirill Scherba <kirill@scherba.ru>. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Config helper go package. File module loads and saves config files. Files
+// are saved atomically and may keep rotated backups.
+
+package conf
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+)
+
+// DefaultPerm is the permission of new config files.
+const DefaultPerm fs.FileMode = 0644
+
+// File is a config file. It loads config file to a struct or a map and saves
+// it back.
+//
+// File methods hold the advisory lock of the config file while reading and
+// writing it, so readers and writers using File in different processes are
+// serialized. See Lock for details.
+type File struct {
+	Path        string        // Config file path
+	Backups     int           // Number of rotated backups kept on save, 0 - no backups
+	LockTimeout time.Duration // Lock wait timeout, DefaultLockTimeout if zero
+
+	doc  *Document // Loaded or saved document
+	stat fileStat  // Loaded or saved file state
+}
+
+// fileStat is a config file state used to detect external modifications.
+type fileStat struct {
+	sum     [sha256.Size]byte // Content hash
+	modTime time.Time         // File modification time
+}
+
+// Backup describes a config file backup.
+type Backup struct {
+	Index   int       // Backup index, 1 is the newest
+	Path    string    // Backup file path
+	ModTime time.Time // Backup modification time
+}
+
+// NewFile creates and returns new config file.
+func NewFile(path string) *File {
+	return &File{Path: path}
+}
+
+// Load reads the config file and decodes it to the value pointed to by v. The
+// file may be JSON, JSON with comments or JSON5.
+func Load(path string, v any) error {
+	return NewFile(path).Load(v)
+}
+
+// Save encodes v to JSON and writes it to the config file atomically.
+func Save(path string, v any) error {
+	return NewFile(path).Save(v)
+}
+
+// Load reads the config file and decodes it to the value pointed to by v. The
+// file may be JSON, JSON with comments or JSON5.
+//
+// The file content hash and modification time are recorded to detect external
+// modifications of the file when it is saved.
+func (f *File) Load(v any) error {
+	return f.LoadContext(context.Background(), v)
+}
+
+// LoadContext is like Load but waits for the file lock until the context is
+// done.
+func (f *File) LoadContext(ctx context.Context, v any) (err error) {
+	defer func() { trace("load config", "path", f.Path, "error", err) }()
+
+	l, err := f.lock(ctx, true)
+	if err != nil {
+		return
+	}
+	defer l.Unlock()
+
+	doc, stat, err := f.read()
+	if err != nil {
+		return
+	}
+	if err = doc.Decode(v); err != nil {
+		return
+	}
+	f.doc, f.stat = doc, stat
+	return
+}
+
+// Save encodes v to JSON and writes it to the config file atomically.
+//
+// If the file was loaded with Load, changed values are patched into the loaded
+// document and values missing in v are removed from it, so comments and
+// formatting of the file are preserved.
+// Otherwise v is written as indented JSON.
+//
+// If Backups is greater than zero, the previous file content is kept in the
+// rotated backup files Path.1 (the newest) to Path.N.
+//
+// If the file was modified by someone else after it was loaded or saved by
+// this File, Save does not write the file and returns *ConflictError. Use
+// Overwrite to save the file anyway, Load to reload it or Merge to merge
+// external modifications to v.
+func (f *File) Save(v any) error {
+	return f.SaveContext(context.Background(), v)
+}
+
+// SaveContext is like Save but waits for the file lock until the context is
+// done.
+func (f *File) SaveContext(ctx context.Context, v any) (err error) {
+	defer func() { trace("save config", "path", f.Path, "error", err) }()
+
+	l, err := f.lock(ctx, false)
+	if err != nil {
+		return
+	}
+	defer l.Unlock()
+
+	if err = f.checkConflict(v); err != nil {
+		return
+	}
+	return f.overwrite(v)
+}
+
+// Overwrite encodes v to JSON and writes it to the config file atomically
+// without checking external modifications of the file.
+func (f *File) Overwrite(v any) error {
+	return f.OverwriteContext(context.Background(), v)
+}
+
+// OverwriteContext is like Overwrite but waits for the file lock until the
+// context is done.
+func (f *File) OverwriteContext(ctx context.Context, v any) (err error) {
+	defer func() {
+		trace("overwrite config", "path", f.Path, "error", err)
+	}()
+
+	l, err := f.lock(ctx, false)
+	if err != nil {
+		return
+	}
+	defer l.Unlock()
+
+	return f.overwrite(v)
+}
+
+// overwrite encodes v and writes it to the locked config file.
+func (f *File) overwrite(v any) (err error) {
+	data, err := f.encode(v)
+	if err != nil {
+		return
+	}
+	return f.write(data)
+}
+
+// Validate validates v like ValidateDir with the config file directory, so
+// relative paths in v are resolved against it.
+func (f *File) Validate(v any) error {
+	return ValidateDir(v, filepath.Dir(f.Path))
+}
+
+// ListBackups returns existing backups of the config file sorted from the
+// newest to the oldest. Backups with indexes from 1 to Backups are listed.
+func (f *File) ListBackups() (backups []Backup, err error) {
+	for index := 1; index <= f.Backups; index++ {
+		path := f.backupPath(index)
+		fi, err := os.Stat(path)
+		switch {
+		case errors.Is(err, fs.ErrNotExist):
+			continue
+		case err != nil:
+			return nil, err
+		}
+		backups = append(backups, Backup{index, path, fi.ModTime()})
+	}
+	return
+}
+
+// Restore replaces the config file with its backup by index from 1 to
+// Backups. The replaced config file content is rotated to backups the same
+// way as in Save.
+func (f *File) Restore(index int) (err error) {
+	defer func() {
+		trace("restore config", "path", f.Path, "backup", index, "error", err)
+	}()
+
+	if index < 1 || index > f.Backups {
+		return fmt.Errorf("backup index %d is out of range from 1 to %d",
+			index, f.Backups)
+	}
+
+	l, err := f.lock(context.Background(), false)
+	if err != nil {
+		return
+	}
+	defer l.Unlock()
+
+	data, err := os.ReadFile(f.backupPath(index))
+	if err != nil {
+		return
+	}
+	return f.write(data)
+}
+
+// encode encodes v to the config file content.
+func (f *File) encode(v any) (data []byte, err error) {
+	if f.doc == nil || f.doc.Root().Kind != NodeObject {
+		return json.MarshalIndent(v, "", "  ")
+	}
+
+	// Patch changed values in the document
+	data, err = json.Marshal(v)
+	if err != nil {
+		return
+	}
+	n, err := (&parser{src: data}).parse()
+	if err != nil {
+		return
+	}
+	doc := *f.doc
+	if err = doc.update(nil, n, data); err != nil {
+		return
+	}
+	return doc.Bytes(), nil
+}
+
+// read reads and parses the config file and returns its state.
+func (f *File) read() (doc *Document, stat fileStat, err error) {
+	data, err := os.ReadFile(f.Path)
+	if err != nil {
+		return
+	}
+	fi, err := os.Stat(f.Path)
+	if err != nil {
+		return
+	}
+	doc, err = ParseJSONC(data)
+	if err != nil {
+		err = fmt.Errorf("can't parse %s: %w", f.Path, err)
+		return
+	}
+	stat = fileStat{sha256.Sum256(data), fi.ModTime()}
+	return
+}
+
+// write writes data to the config file atomically, rotates backups and
+// records the saved file state.
+func (f *File) write(data []byte) (err error) {
+	if err = writeFile(f.Path, data, DefaultPerm, f.rotate); err != nil {
+		return
+	}
+	fi, err := os.Stat(f.Path)
+	if err != nil {
+		return
+	}
+	if f.doc == nil || !bytes.Equal(f.doc.Bytes(), data) {
+		if f.doc, err = ParseJSONC(data); err != nil {
+			return
+		}
+	}
+	f.stat = fileStat{sha256.Sum256(data), fi.ModTime()}
+	return
+}
+
+// rotate rotates config file backups. It is called before the new config file
+// content replaces the old one.
+func (f *File) rotate() (err error) {
+	if f.Backups <= 0 {
+		return
+	}
+	if _, err = os.Stat(f.Path); errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+
+	// Shift existing backups
+	os.Remove(f.backupPath(f.Backups))
+	for i := f.Backups - 1; i >= 1; i-- {
+		err = os.Rename(f.backupPath(i), f.backupPath(i+1))
+		if err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return
+		}
+	}
+
+	// Keep current file as the newest backup. The hard link is used to keep
+	// the config file in place until it is atomically replaced.
+	if err = os.Link(f.Path, f.backupPath(1)); err == nil {
+		return
+	}
+	return copyFile(f.Path, f.backupPath(1))
+}
+
+// backupPath returns path of the backup by index.
+func (f *File) backupPath(index int) string {
+	return f.Path + "." + strconv.Itoa(index)
+}
+
+// WriteFile writes data to the named file atomically. The data is written to a
+// temporary file in the same directory, synced to disk and renamed to name, so
+// readers see either the old or the new file content and never a truncated
+// file. If the file exists, its permissions and ownership are preserved,
+// otherwise the file is created with permissions perm.
+func WriteFile(name string, data []byte, perm fs.FileMode) error {
+	return writeFile(name, data, perm, nil)
+}
+
+// writeFile writes data to the named file atomically and calls before function
+// just before the temporary file is renamed to name.
+func writeFile(name string, data []byte, perm fs.FileMode, before func() error) (err error) {
+	dir, base := filepath.Split(name)
+	if dir == "" {
+		dir = "."
+	}
+
+	// Create temporary file and remove it on error
+	tmp, err := os.CreateTemp(dir, "."+base+".*.tmp")
+	if err != nil {
+		return
+	}
+	defer func() {
+		if err != nil {
+			tmp.Close()
+			os.Remove(tmp.Name())
+		}
+	}()
+
+	// Preserve permissions and ownership of existing file
+	fi, statErr := os.Stat(name)
+	if statErr == nil {
+		perm = fi.Mode().Perm()
+		if err = chown(tmp, fi); err != nil {
+			return
+		}
+	}
+	if err = tmp.Chmod(perm); err != nil {
+		return
+	}
+
+	// Write and sync data
+	if _, err = tmp.Write(data); err != nil {
+		return
+	}
+	if err = tmp.Sync(); err != nil {
+		return
+	}
+	if err = tmp.Close(); err != nil {
+		return
+	}
+
+	// Replace the file
+	if before != nil {
+		if err = before(); err != nil {
+			return
+		}
+	}
+	if err = os.Rename(tmp.Name(), name); err != nil {
+		return
+	}
+	return syncDir(dir)
+}
+
+// copyFile copies src file content and permissions to dst file.
+func copyFile(src, dst string) (err error) {
+	in, err := os.Open(src)
+	if err != nil {
+		return
+	}
+	defer in.Close()
+
+	fi, err := in.Stat()
+	if err != nil {
+		return
+	}
+	out, err := os.OpenFile(dst, os.O_WRONLY|os.O_CREATE|os.O_TRUNC,
+		fi.Mode().Perm())
+	if err != nil {
+		return
+	}
+	if _, err = io.Copy(out, in); err != nil {
+		out.Close()
+		return
+	}
+	return out.Close()
+}
@@ -0,0 +1,18 @@
+// Copyright 2024 Kirill Scherba <kirill@scherba.ru>. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build !unix
+
+package conf
+
+import (
+	"io/fs"
+	"os"
+)
+
+// chown does nothing on systems without unix file ownership.
+func chown(f *os.File, fi fs.FileInfo) error { return nil }
+
+// syncDir does nothing on systems where directories can't be synced.
+func syncDir(dir string) error { return nil }
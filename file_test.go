@@ -0,0 +1,278 @@
+package conf
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestFileSave(t *testing.T) {
+
+	path := filepath.Join(t.TempDir(), "config.json")
+	if err := os.WriteFile(path, []byte(jsoncData), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	type config struct {
+		Name string  `json:"name"`
+		Age  float64 `json:"age"`
+		On   bool    `json:"on"`
+	}
+
+	file := NewFile(path)
+	file.Backups = 2
+
+	var c config
+	if err := file.Load(&c); err != nil {
+		t.Fatal(err)
+	}
+	if c.Name != "John Smith" || c.Age != 57 {
+		t.Fatalf("wrong loaded config: %+v", c)
+	}
+
+	// Save three times to rotate backups
+	for _, name := range []string{"A", "B", "C"} {
+		c.Name = name
+		if err := file.Save(c); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Comments and permissions are preserved
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), `"name": "C", // trailing comment`) {
+		t.Fatalf("comments should be preserved:\n%s", data)
+	}
+	if fi, _ := os.Stat(path); fi.Mode().Perm() != 0600 {
+		t.Fatalf("wrong permissions %v", fi.Mode().Perm())
+	}
+
+	// Backups
+	backups, err := file.ListBackups()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(backups) != 2 || backups[0].Index != 1 || backups[1].Index != 2 {
+		t.Fatalf("wrong backups: %v", backups)
+	}
+
+	if err = file.Restore(2); err != nil {
+		t.Fatal(err)
+	}
+	if err = Load(path, &c); err != nil {
+		t.Fatal(err)
+	}
+	if c.Name != "A" {
+		t.Fatalf("restored name should be A, got %s", c.Name)
+	}
+
+	// No temporary files left
+	matches, _ := filepath.Glob(filepath.Join(filepath.Dir(path), ".*.tmp"))
+	if len(matches) > 0 {
+		t.Fatalf("temporary files left: %v", matches)
+	}
+}
+
+func TestFileConflict(t *testing.T) {
+
+	path := filepath.Join(t.TempDir(), "config.json")
+	data := []byte(`{"name": "John", "age": 57, "on": false}`)
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	type config struct {
+		Name string  `json:"name"`
+		Age  float64 `json:"age"`
+		On   bool    `json:"on"`
+	}
+
+	file := NewFile(path)
+	var c config
+	if err := file.Load(&c); err != nil {
+		t.Fatal(err)
+	}
+
+	// Modify the file externally
+	data = []byte(`{"name": "Jane", "age": 58, "on": false}`)
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	c.Age = 60
+	c.On = true
+	err := file.Save(c)
+	var conflict *ConflictError
+	if !errors.As(err, &conflict) || !errors.Is(err, ErrConflict) {
+		t.Fatalf("save should return conflict error, got %v", err)
+	}
+	if len(conflict.Fields) != 2 {
+		t.Fatalf("wrong conflict fields: %v", conflict.Fields)
+	}
+	if f := conflict.Fields[0]; f.Name != "age" || !f.Conflict() ||
+		f.Theirs != "58" || f.Ours != "60" {
+		t.Fatalf("wrong age conflict: %+v", f)
+	}
+	if f := conflict.Fields[1]; f.Name != "name" || f.Conflict() {
+		t.Fatalf("wrong name conflict: %+v", f)
+	}
+
+	// Merge external changes and save
+	if err = file.Merge(&c); err != nil {
+		t.Fatal(err)
+	}
+	if c.Name != "Jane" || c.Age != 60 || !c.On {
+		t.Fatalf("wrong merged config: %+v", c)
+	}
+	if err = file.Save(c); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestFileSaveNested(t *testing.T) {
+
+	path := filepath.Join(t.TempDir(), "config.json")
+	data := "{\n  \"db\": {\n    // host comment\n    \"host\": \"a\",\n    \"port\": 1\n  }\n}\n"
+	if err := os.WriteFile(path, []byte(data), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	type config struct {
+		DB struct {
+			Host string `json:"host"`
+			Port int    `json:"port"`
+		} `json:"db"`
+	}
+
+	file := NewFile(path)
+	var c config
+	if err := file.Load(&c); err != nil {
+		t.Fatal(err)
+	}
+	c.DB.Port = 2
+	if err := file.Save(c); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := strings.Replace(data, `"port": 1`, `"port": 2`, 1); string(got) != want {
+		t.Fatalf("got:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestFileSaveRemoved(t *testing.T) {
+
+	path := filepath.Join(t.TempDir(), "config.json")
+	data := "{\n  \"name\": \"a\", // name\n  \"gone\": 2,\n  \"tag\": \"x\"\n}\n"
+	if err := os.WriteFile(path, []byte(data), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	// Map member removed in the editor
+	file := NewFile(path)
+	var m map[string]any
+	if err := file.Load(&m); err != nil {
+		t.Fatal(err)
+	}
+	delete(m, "gone")
+	if err := file.Save(m); err != nil {
+		t.Fatal(err)
+	}
+
+	// Empty struct field with omitempty
+	var c struct {
+		Name string `json:"name"`
+		Tag  string `json:"tag,omitempty"`
+	}
+	if err := file.Load(&c); err != nil {
+		t.Fatal(err)
+	}
+	c.Tag = ""
+	if err := file.Save(c); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "{\n  \"name\": \"a\" // name\n}\n"; string(got) != want {
+		t.Fatalf("got:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestFileSaveOrder(t *testing.T) {
+
+	path := filepath.Join(t.TempDir(), "config.json")
+	data := "{\n  \"headers\": {\"a\": \"1\", \"b\": \"2\"}\n}\n"
+	if err := os.WriteFile(path, []byte(data), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	// Ordered object, e.g. types.KeyValue
+	var c struct {
+		Headers json.RawMessage `json:"headers"`
+	}
+	file := NewFile(path)
+	if err := file.Load(&c); err != nil {
+		t.Fatal(err)
+	}
+	c.Headers = json.RawMessage(`{"b":"2","a":"1"}`)
+	if err := file.Save(c); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "{\n  \"headers\": {\"b\":\"2\",\"a\":\"1\"}\n}\n"; string(got) != want {
+		t.Fatalf("got:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestFileBackups(t *testing.T) {
+
+	// Glob special characters in the path and lock file siblings
+	path := filepath.Join(t.TempDir(), "conf[1]*.json")
+	file := NewFile(path)
+	file.Backups = 2
+	for _, name := range []string{"A", "B", "C"} {
+		if err := file.Save(map[string]any{"name": name}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	os.WriteFile(path+".3", nil, 0600)
+
+	backups, err := file.ListBackups()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(backups) != 2 || backups[0].Path != path+".1" ||
+		backups[1].Path != path+".2" {
+		t.Fatalf("wrong backups: %v", backups)
+	}
+
+	for _, index := range []int{0, -1, 3} {
+		if err = file.Restore(index); err == nil ||
+			!strings.Contains(err.Error(), "out of range") {
+			t.Fatalf("%d: got error %v, want out of range", index, err)
+		}
+	}
+	if err = file.Restore(1); err != nil {
+		t.Fatal(err)
+	}
+	var c map[string]any
+	if err = Load(path, &c); err != nil || c["name"] != "B" {
+		t.Fatalf("restored name should be B, got %v %v", c, err)
+	}
+}
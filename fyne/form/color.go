@@ -0,0 +1,65 @@
+// Copyright 2024 Kirill Scherba <kirill@scherba.ru>. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Color widget.
+
+package form
+
+import (
+	"image/color"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/canvas"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+	"github.com/teonet-go/conf/types"
+)
+
+// newColor creates color entry with swatch button which opens color picker
+// dialog. The swatch shows the color entered in the entry.
+func newColor(d types.Descriptor, value string) fyne.CanvasObject {
+	entry := widget.NewEntry()
+	entry.SetPlaceHolder(d.Placeholder)
+	entry.SetText(value)
+	entry.Validator = d.Validate
+
+	// Create swatch and update it when the entry changes
+	swatch := canvas.NewRectangle(color.Transparent)
+	swatch.StrokeColor = theme.ForegroundColor()
+	swatch.StrokeWidth = 1
+	setSwatch := func(s string) {
+		swatch.FillColor = color.Transparent
+		if c, err := types.Color(s).Parse(); err == nil {
+			swatch.FillColor = c
+		}
+		swatch.Refresh()
+	}
+	setSwatch(value)
+	entry.OnChanged = setSwatch
+
+	var button *widget.Button
+	button = widget.NewButton("", func() {
+		parent := objectWindow(button)
+		if parent == nil {
+			return
+		}
+		dlg := dialog.NewColorPicker("Color", "Choose color",
+			func(c color.Color) { entry.SetText(string(types.NewColor(c))) },
+			parent,
+		)
+		dlg.Advanced = true
+		if c, err := types.Color(entry.Text).Parse(); err == nil {
+			dlg.SetColor(c)
+		}
+		dlg.Show()
+	})
+	swatchButton := container.NewStack(button, container.NewPadded(swatch))
+
+	return container.NewBorder(nil, nil, swatchButton, nil, entry)
+}
+
+// colorValue returns color widget value.
+func colorValue(w fyne.CanvasObject) string { return containerEntry(w).Text }
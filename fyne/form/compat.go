@@ -0,0 +1,26 @@
+// Copyright 2024 Kirill Scherba <kirill@scherba.ru>. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Deprecated types package widget functions support.
+
+package form
+
+import (
+	"fyne.io/fyne/v2"
+	"github.com/teonet-go/conf"
+	"github.com/teonet-go/conf/internal/compat"
+)
+
+// Set hooks of the deprecated types.CheckWidget, types.CheckSave,
+// types.NewWidget and types.GetWidgetValue functions.
+func init() {
+	compat.NewWidget = func(field *conf.Field[fyne.CanvasObject]) (
+		fyne.CanvasObject, bool, bool) {
+		return newSpecialWidget(field, "")
+	}
+	compat.WidgetValue = func(field *conf.Field[fyne.CanvasObject]) (
+		string, bool) {
+		return specialWidgetValue(field, "")
+	}
+}
@@ -0,0 +1,66 @@
+// Copyright 2024 Kirill Scherba <kirill@scherba.ru>. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Config file conflict dialog.
+
+package form
+
+import (
+	"fmt"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+	"github.com/teonet-go/conf"
+)
+
+// ShowConflict shows dialog with fields modified in the config file by
+// someone else and "Overwrite", "Reload" and "Merge" buttons. The dialog
+// calls overwrite, reload or merge callback when the button is pressed.
+func ShowConflict(e *conf.ConflictError, overwrite, reload, merge func(),
+	parent fyne.Window) {
+
+	// Fields table with header
+	grid := container.NewGridWithColumns(3,
+		widget.NewLabelWithStyle("Field", fyne.TextAlignLeading,
+			fyne.TextStyle{Bold: true}),
+		widget.NewLabelWithStyle("File value", fyne.TextAlignLeading,
+			fyne.TextStyle{Bold: true}),
+		widget.NewLabelWithStyle("Your value", fyne.TextAlignLeading,
+			fyne.TextStyle{Bold: true}),
+	)
+	for _, field := range e.Fields {
+		name := field.Name
+		if field.Conflict() {
+			name += " (conflict)"
+		}
+		grid.Add(widget.NewLabel(name))
+		grid.Add(widget.NewLabel(field.Theirs))
+		grid.Add(widget.NewLabel(field.Ours))
+	}
+
+	message := widget.NewLabel(fmt.Sprintf(
+		"The file %s was modified at %s by someone else.",
+		e.Path, e.ModTime.Format("2006-01-02 15:04:05")))
+	content := container.NewVBox(message, grid)
+
+	// Dialog with buttons
+	d := dialog.NewCustomWithoutButtons("Config file conflict", content, parent)
+	button := func(label string, f func()) *widget.Button {
+		return widget.NewButton(label, func() {
+			d.Hide()
+			if f != nil {
+				f()
+			}
+		})
+	}
+	d.SetButtons([]fyne.CanvasObject{
+		button("Cancel", nil),
+		button("Reload", reload),
+		button("Merge", merge),
+		button("Overwrite", overwrite),
+	})
+	d.Show()
+}
@@ -0,0 +1,50 @@
+// Copyright 2024 Kirill Scherba <kirill@scherba.ru>. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Config differences dialog.
+
+package form
+
+import (
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+	"github.com/teonet-go/conf"
+)
+
+// ShowDiff shows confirmation dialog with config differences. Secret values
+// are shown redacted. The confirm callback is called when the "Save" button is
+// pressed.
+func ShowDiff(d conf.Differences, confirm func(), parent fyne.Window) {
+
+	// Differences table with header
+	grid := container.NewGridWithColumns(3,
+		widget.NewLabelWithStyle("Field", fyne.TextAlignLeading,
+			fyne.TextStyle{Bold: true}),
+		widget.NewLabelWithStyle("Old value", fyne.TextAlignLeading,
+			fyne.TextStyle{Bold: true}),
+		widget.NewLabelWithStyle("New value", fyne.TextAlignLeading,
+			fyne.TextStyle{Bold: true}),
+	)
+	for _, diff := range d {
+		grid.Add(widget.NewLabel(diff.Path))
+		grid.Add(widget.NewLabel(diff.Old))
+		grid.Add(widget.NewLabel(diff.New))
+	}
+
+	var content fyne.CanvasObject = grid
+	if len(d) == 0 {
+		content = widget.NewLabel("Nothing changed.")
+	}
+
+	dialog.ShowCustomConfirm("Save changes", "Save", "Cancel", content,
+		func(ok bool) {
+			if ok {
+				confirm()
+			}
+		},
+		parent,
+	)
+}
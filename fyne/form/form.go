@@ -6,27 +6,52 @@
 package form
 
 import (
+	"errors"
 	"fmt"
 
 	"fyne.io/fyne/v2"
 	"fyne.io/fyne/v2/widget"
 	"github.com/teonet-go/conf"
-	"github.com/teonet-go/conf/types"
 )
 
 // Form is a widget that creates fine-go form widget.
 type Form struct {
 	*widget.Form
-	fields conf.Fields[fyne.CanvasObject]
+	fields  conf.Fields[fyne.CanvasObject]
+	confirm fyne.Window // Parent window of the save confirmation dialog
+	dir     string      // Config file directory of relative paths
 }
 
-// New creates and returns new form.
+// New creates and returns new form. It panics if the o type is not supported.
 func New(o any) *Form {
-	f := &Form{Form: widget.NewForm()}
-	f.getFields(o)
+	f, err := NewE(o)
+	if err != nil {
+		panic(err)
+	}
 	return f
 }
 
+// NewE creates and returns new form or an error wrapping
+// conf.ErrUnsupportedType if the o type is not supported.
+func NewE(o any) (f *Form, err error) {
+	return NewDirE(o, "")
+}
+
+// NewDirE is like NewE but relative paths of path fields are resolved against
+// the config file directory dir, e.g. filepath.Dir(file.Path).
+func NewDirE(o any, dir string) (f *Form, err error) {
+	f = &Form{Form: widget.NewForm(), dir: dir}
+	err = f.getFields(o)
+	return
+}
+
+// SetConfirm sets the parent window of the save confirmation dialog. When it
+// is set, the save button shows the dialog with changed fields and saves the
+// form only when the changes are confirmed.
+func (f *Form) SetConfirm(parent fyne.Window) {
+	f.confirm = parent
+}
+
 // NewSaveButton creates and returns save button.
 func (f *Form) NewSaveButton(o any, save func(), valerr func(err error)) *widget.Button {
 
@@ -37,40 +62,88 @@ func (f *Form) NewSaveButton(o any, save func(), valerr func(err error)) *widget
 			valerr(err)
 			return
 		}
+		if err := f.validateSpecial(); err != nil {
+			valerr(err)
+			return
+		}
 
-		// Update fields values
-		f.fields.SetValues(o, func(field *conf.Field[fyne.CanvasObject]) (string, bool) {
-			switch field.Type {
-
-			// Bool fields
-			case "bool":
-				val := field.Entry.(*widget.Check).Checked
-				return fmt.Sprintf("%v", val), true
+		// Set fields values to the copy of o and validate it with the config
+		// types Validate methods
+		c := conf.Clone(o)
+		if err := f.setValues(c); err != nil {
+			valerr(err)
+			return
+		}
+		if err := conf.ValidateDir(c, f.dir); err != nil {
+			valerr(err)
+			return
+		}
 
-			default:
-				// Check special types and sets it value
-				if types.CheckSave(field) {
-					return "", false
+		// Show changes made in the copy of o and update fields values after
+		// confirmation
+		if f.confirm != nil {
+			ShowDiff(conf.Diff(o, c), func() {
+				if err := f.setValues(o); err != nil {
+					valerr(err)
+					return
 				}
+				save()
+			}, f.confirm)
+			return
+		}
 
-				// Any other simple fields displayed as string: string, int,
-				// float, etc.
-				return field.Entry.(*widget.Entry).Text, true
-			}
-		})
+		// Update fields values, o is not changed if any value can't be set
+		if err := f.setValues(o); err != nil {
+			valerr(err)
+			return
+		}
 
 		// Use save callback to encode json and Write back to the file
 		save()
 	})
 }
 
-// getFields gets fields from object and adds them to the form.
-func (f *Form) getFields(o any) {
-	f.fields = conf.GetFields(o, func(field *conf.Field[fyne.CanvasObject]) {
-		f.append(field)
+// validateSpecial validates widget values of special field types which widgets
+// have no validators, e.g. selects and check groups.
+func (f *Form) validateSpecial() error {
+	var errs []error
+	for _, field := range f.fields {
+		errs = append(errs, validateSpecialWidget(field, f.dir))
+	}
+	return errors.Join(errs...)
+}
+
+// setValues sets form fields values to o.
+func (f *Form) setValues(o any) error {
+	return f.fields.SetValues(o, func(field *conf.Field[fyne.CanvasObject]) (string, bool) {
+		switch field.Type {
+
+		// Bool fields
+		case "bool":
+			val := field.Entry.(*widget.Check).Checked
+			return fmt.Sprintf("%v", val), true
+
+		default:
+			// Check special types and sets it value
+			if saveSpecialWidget(field, f.dir) {
+				return "", false
+			}
+
+			// Any other simple fields displayed as string: string, int,
+			// float, etc.
+			return field.Entry.(*widget.Entry).Text, true
+		}
 	})
 }
 
+// getFields gets fields from object and adds them to the form.
+func (f *Form) getFields(o any) (err error) {
+	f.fields, err = conf.GetFieldsE(o,
+		func(field *conf.Field[fyne.CanvasObject]) { f.append(field) },
+	)
+	return
+}
+
 // append adds a new field to the form.
 func (f *Form) append(field *conf.Field[fyne.CanvasObject]) {
 
@@ -93,7 +166,7 @@ func (f *Form) append(field *conf.Field[fyne.CanvasObject]) {
 	default:
 
 		// Check special types and create its widget
-		if widget, hint, ok := types.CheckWidget(field); ok {
+		if widget, hint, ok := newSpecialWidget(field, f.dir); ok {
 			h = hint
 			w = widget
 			d = field.NameDisplay
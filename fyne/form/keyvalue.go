@@ -0,0 +1,100 @@
+// Copyright 2024 Kirill Scherba <kirill@scherba.ru>. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Key and value pairs table widget.
+
+package form
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+	"github.com/teonet-go/conf/types"
+)
+
+// newKeyValue creates editable two-column table of key and value pairs with
+// add and remove row buttons. The widget is a vertical box of the header,
+// the rows box and the add button, each row is a border container of the key
+// and value grid and the remove button.
+func newKeyValue(d types.Descriptor, value string) fyne.CanvasObject {
+	rows := container.NewVBox()
+
+	// newRow creates table row, key entry validator checks the key with
+	// descriptor and reports keys duplicated in other rows
+	newRow := func(key, value string) *fyne.Container {
+		keyEntry := widget.NewEntry()
+		keyEntry.SetPlaceHolder("Key")
+		keyEntry.SetText(key)
+		valueEntry := widget.NewEntry()
+		valueEntry.SetPlaceHolder("Value")
+		valueEntry.SetText(value)
+
+		var row *fyne.Container
+		keyEntry.Validator = func(s string) error {
+			if d.Validate != nil {
+				data, _ := json.Marshal(map[string]string{s: ""})
+				if err := d.Validate(string(data)); err != nil {
+					return err
+				}
+			}
+			for _, r := range rows.Objects {
+				if r != row && keyValueRow(r).Key == s {
+					return fmt.Errorf("duplicate key %q", s)
+				}
+			}
+			return nil
+		}
+
+		remove := widget.NewButtonWithIcon("", theme.DeleteIcon(), func() {
+			rows.Remove(row)
+		})
+		row = container.NewBorder(nil, nil, nil, remove,
+			container.NewGridWithColumns(2, keyEntry, valueEntry))
+		return row
+	}
+
+	var kv types.KeyValue
+	kv.UnmarshalJSON([]byte(value))
+	for _, p := range kv.Pairs {
+		rows.Add(newRow(p.Key, p.Value))
+	}
+
+	header := container.NewGridWithColumns(2,
+		widget.NewLabelWithStyle("Key", fyne.TextAlignLeading,
+			fyne.TextStyle{Bold: true}),
+		widget.NewLabelWithStyle("Value", fyne.TextAlignLeading,
+			fyne.TextStyle{Bold: true}),
+	)
+	add := widget.NewButtonWithIcon("Add", theme.ContentAddIcon(), func() {
+		rows.Add(newRow("", ""))
+	})
+
+	return container.NewVBox(header, rows, add)
+}
+
+// keyValueValue returns key and value pairs table widget value encoded as
+// JSON object.
+func keyValueValue(w fyne.CanvasObject) string {
+	var kv types.KeyValue
+	rows := w.(*fyne.Container).Objects[1].(*fyne.Container)
+	for _, r := range rows.Objects {
+		kv.Pairs = append(kv.Pairs, keyValueRow(r))
+	}
+	return kv.GetValue()
+}
+
+// keyValueRow returns key and value pair of the table row.
+func keyValueRow(r fyne.CanvasObject) (p types.Pair) {
+	for _, o := range r.(*fyne.Container).Objects {
+		if grid, ok := o.(*fyne.Container); ok {
+			p.Key = grid.Objects[0].(*widget.Entry).Text
+			p.Value = grid.Objects[1].(*widget.Entry).Text
+		}
+	}
+	return
+}
@@ -0,0 +1,75 @@
+// Copyright 2024 Kirill Scherba <kirill@scherba.ru>. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Config merge conflicts dialog.
+
+package form
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+	"github.com/teonet-go/conf"
+)
+
+// ShowMerge shows dialog to resolve merge conflicts returned by conf.Merge3
+// field by field. Each conflict is resolved with our or their value chosen by
+// user and set to the merged value p points to. The done callback is called
+// with resolve error when the "Apply" button is pressed.
+func ShowMerge(conflicts []conf.MergeConflict, p any, done func(err error),
+	parent fyne.Window) {
+
+	// Conflicts table with header
+	grid := container.NewGridWithColumns(3,
+		widget.NewLabelWithStyle("Field", fyne.TextAlignLeading,
+			fyne.TextStyle{Bold: true}),
+		widget.NewLabelWithStyle("Base value", fyne.TextAlignLeading,
+			fyne.TextStyle{Bold: true}),
+		widget.NewLabelWithStyle("Use value", fyne.TextAlignLeading,
+			fyne.TextStyle{Bold: true}),
+	)
+	choices := make([]*widget.RadioGroup, len(conflicts))
+	for i, c := range conflicts {
+		choices[i] = widget.NewRadioGroup([]string{
+			"Mine: " + mergeValueStr(c.Ours, c.OursExists),
+			"Theirs: " + mergeValueStr(c.Theirs, c.TheirsExists),
+		}, nil)
+		choices[i].SetSelected(choices[i].Options[0])
+		choices[i].Required = true
+		grid.Add(widget.NewLabel(c.Path))
+		grid.Add(widget.NewLabel(mergeValueStr(c.Base, c.BaseExists)))
+		grid.Add(choices[i])
+	}
+
+	dialog.ShowCustomConfirm("Merge conflicts", "Apply", "Cancel", grid,
+		func(ok bool) {
+			if !ok {
+				return
+			}
+			var errs []error
+			for i, c := range conflicts {
+				theirs := slices.Index(choices[i].Options,
+					choices[i].Selected) == 1
+				errs = append(errs, c.Resolve(p, theirs))
+			}
+			if done != nil {
+				done(errors.Join(errs...))
+			}
+		},
+		parent,
+	)
+}
+
+// mergeValueStr returns the conflict value as string.
+func mergeValueStr(v any, exists bool) string {
+	if !exists {
+		return "(none)"
+	}
+	return fmt.Sprintf("%v", v)
+}
@@ -0,0 +1,122 @@
+// Copyright 2024 Kirill Scherba <kirill@scherba.ru>. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// File and directory path widgets.
+
+package form
+
+import (
+	"path/filepath"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/storage"
+	"fyne.io/fyne/v2/widget"
+	"github.com/teonet-go/conf/types"
+)
+
+// newFilePath creates file path entry with "Browse…" button which opens file
+// dialog.
+func newFilePath(d types.Descriptor, value string) fyne.CanvasObject {
+	return newPathEntry(d, value, false)
+}
+
+// newDirPath creates directory path entry with "Browse…" button which opens
+// folder dialog.
+func newDirPath(d types.Descriptor, value string) fyne.CanvasObject {
+	return newPathEntry(d, value, true)
+}
+
+// newPathEntry creates path entry with "Browse…" button.
+func newPathEntry(d types.Descriptor, value string, dir bool) fyne.CanvasObject {
+	entry := widget.NewEntry()
+	entry.SetPlaceHolder(d.Placeholder)
+	entry.SetText(value)
+	entry.Validator = d.Validate
+
+	// Set selected path to the entry, the path is relative to the base
+	// directory if it is set
+	setPath := func(uri fyne.URI) {
+		path := uri.Path()
+		if d.BaseDir != "" {
+			base, _ := filepath.Abs(d.BaseDir)
+			if rel, err := filepath.Rel(base, path); err == nil {
+				path = rel
+			}
+		}
+		entry.SetText(path)
+	}
+
+	var button *widget.Button
+	button = widget.NewButton("Browse…", func() {
+		parent := objectWindow(button)
+		if parent == nil {
+			return
+		}
+		var dlg *dialog.FileDialog
+		if dir {
+			dlg = dialog.NewFolderOpen(func(uri fyne.ListableURI, err error) {
+				if err == nil && uri != nil {
+					setPath(uri)
+				}
+			}, parent)
+		} else {
+			dlg = dialog.NewFileOpen(func(r fyne.URIReadCloser, err error) {
+				if err == nil && r != nil {
+					r.Close()
+					setPath(r.URI())
+				}
+			}, parent)
+			if len(d.Extensions) > 0 {
+				dlg.SetFilter(storage.NewExtensionFileFilter(d.Extensions))
+			}
+		}
+		dlg.SetLocation(pathLocation(entry.Text, d.BaseDir, dir))
+		dlg.Show()
+	})
+
+	return container.NewBorder(nil, nil, nil, button, entry)
+}
+
+// pathValue returns path entry widget value.
+func pathValue(w fyne.CanvasObject) string { return containerEntry(w).Text }
+
+// pathLocation returns the directory of the path to start the dialog in or
+// nil if it does not exist.
+func pathLocation(path, base string, dir bool) fyne.ListableURI {
+	if path == "" {
+		return nil
+	}
+	if base != "" && !filepath.IsAbs(path) {
+		path = filepath.Join(base, path)
+	}
+	if !dir {
+		path = filepath.Dir(path)
+	}
+	path, err := filepath.Abs(path)
+	if err != nil {
+		return nil
+	}
+	l, err := storage.ListerForURI(storage.NewFileURI(path))
+	if err != nil {
+		return nil
+	}
+	return l
+}
+
+// objectWindow returns the window which shows the object or nil.
+func objectWindow(o fyne.CanvasObject) fyne.Window {
+	app := fyne.CurrentApp()
+	if app == nil {
+		return nil
+	}
+	c := app.Driver().CanvasForObject(o)
+	for _, w := range app.Driver().AllWindows() {
+		if w.Canvas() == c {
+			return w
+		}
+	}
+	return nil
+}
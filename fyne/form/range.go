@@ -0,0 +1,62 @@
+// Copyright 2024 Kirill Scherba <kirill@scherba.ru>. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Numeric range widget.
+
+package form
+
+import (
+	"strconv"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/widget"
+	"github.com/teonet-go/conf/types"
+)
+
+// newRange creates slider with live value label. The text entry is created if
+// the range has no bounds.
+func newRange(d types.Descriptor, value string) fyne.CanvasObject {
+	if d.Min >= d.Max {
+		return newEntry(d, value)
+	}
+
+	v, _ := strconv.ParseFloat(value, 64)
+	slider := widget.NewSlider(d.Min, d.Max)
+	slider.Step = d.Step
+	slider.Value = v
+
+	label := widget.NewLabel(formatRange(v, d.Step))
+	slider.OnChanged = func(v float64) { label.SetText(formatRange(v, d.Step)) }
+
+	return container.NewBorder(nil, nil, nil, label, slider)
+}
+
+// rangeValue returns slider widget value shown in its label or text entry
+// value.
+func rangeValue(w fyne.CanvasObject) string {
+	if e, ok := w.(*widget.Entry); ok {
+		return e.Text
+	}
+	for _, o := range w.(*fyne.Container).Objects {
+		if label, ok := o.(*widget.Label); ok {
+			return label.Text
+		}
+	}
+	return ""
+}
+
+// formatRange formats the slider value with the number of decimals of step.
+func formatRange(v, step float64) string {
+	prec := -1
+	if step > 0 {
+		s := strconv.FormatFloat(step, 'f', -1, 64)
+		prec = 0
+		if i := strings.IndexByte(s, '.'); i >= 0 {
+			prec = len(s) - i - 1
+		}
+	}
+	return strconv.FormatFloat(v, 'f', prec, 64)
+}
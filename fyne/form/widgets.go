@@ -0,0 +1,216 @@
+// Copyright 2024 Kirill Scherba <kirill@scherba.ru>. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Special field types widgets.
+
+package form
+
+import (
+	"encoding/json"
+	"fmt"
+	"sync"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/widget"
+	"github.com/teonet-go/conf"
+	"github.com/teonet-go/conf/types"
+)
+
+// Widget creates Fyne widgets of the special fields editor kind.
+type Widget struct {
+	// New creates the widget for the field descriptor and value
+	New func(d types.Descriptor, value string) fyne.CanvasObject
+
+	// Value returns the value edited in the widget
+	Value func(w fyne.CanvasObject) string
+}
+
+// widgets contains registered widgets by editor kind.
+var widgets = struct {
+	sync.RWMutex
+	kinds map[types.Kind]Widget
+}{kinds: map[types.Kind]Widget{
+	types.KindText:        {newEntry, entryValue},
+	types.KindEmail:       {newEntry, entryValue},
+	types.KindPassword:    {newEntry, entryValue},
+	types.KindMultiline:   {newEntry, entryValue},
+	types.KindRadio:       {newRadioGroup, radioGroupValue},
+	types.KindSelect:      {newSelect, selectValue},
+	types.KindSelectEntry: {newSelectEntry, entryValue},
+	types.KindCheckGroup:  {newCheckGroup, checkGroupValue},
+	types.KindURL:         {newEntry, entryValue},
+	types.KindIP:          {newEntry, entryValue},
+	types.KindCIDR:        {newEntry, entryValue},
+	types.KindHostPort:    {newEntry, entryValue},
+	types.KindPort:        {newEntry, entryValue},
+	types.KindFilePath:    {newFilePath, pathValue},
+	types.KindDirPath:     {newDirPath, pathValue},
+	types.KindColor:       {newColor, colorValue},
+	types.KindRange:       {newRange, rangeValue},
+	types.KindKeyValue:    {newKeyValue, keyValueValue},
+}}
+
+// RegisterWidget registers the widget of the editor kind. Application packages
+// use it to show their own kinds of special field types.
+func RegisterWidget(kind types.Kind, w Widget) {
+	widgets.Lock()
+	defer widgets.Unlock()
+	widgets.kinds[kind] = w
+}
+
+// kindWidget returns the registered widget by editor kind.
+func kindWidget(kind types.Kind) (w Widget, ok bool) {
+	widgets.RLock()
+	defer widgets.RUnlock()
+	w, ok = widgets.kinds[kind]
+	return
+}
+
+// newSpecialWidget creates and returns widget of the special field type, true
+// if hint for this field is supported and true if the field type is
+// registered. Relative paths are resolved against the config file directory.
+func newSpecialWidget(field *conf.Field[fyne.CanvasObject], dir string) (
+	w fyne.CanvasObject, h, ok bool) {
+
+	d, ok := types.DescribeFieldDir(field, dir)
+	if !ok {
+		return
+	}
+	kw, ok := kindWidget(d.Kind)
+	if !ok {
+		return
+	}
+	value, _ := types.GetFieldValue(field)
+	return kw.New(d, value), d.Hint, true
+}
+
+// saveSpecialWidget sets the widget value to the special field type and
+// returns true if the field type is registered.
+func saveSpecialWidget(field *conf.Field[fyne.CanvasObject], dir string) bool {
+	val, ok := specialWidgetValue(field, dir)
+	if !ok {
+		return false
+	}
+	return types.SetFieldValue(field, val)
+}
+
+// specialWidgetValue returns the widget value of the special field type and
+// true if the field type is registered.
+func specialWidgetValue(field *conf.Field[fyne.CanvasObject], dir string) (
+	val string, ok bool) {
+
+	d, ok := types.DescribeFieldDir(field, dir)
+	if !ok {
+		return
+	}
+	kw, ok := kindWidget(d.Kind)
+	if !ok || field.Entry == nil {
+		return "", false
+	}
+	return kw.Value(field.Entry), true
+}
+
+// validateSpecialWidget validates the widget value of the special field type
+// with the field descriptor.
+func validateSpecialWidget(field *conf.Field[fyne.CanvasObject], dir string) error {
+	d, ok := types.DescribeFieldDir(field, dir)
+	if !ok || d.Validate == nil {
+		return nil
+	}
+	kw, ok := kindWidget(d.Kind)
+	if !ok {
+		return nil
+	}
+	if err := d.Validate(kw.Value(field.Entry)); err != nil {
+		return fmt.Errorf("%s: %w", field.NameDisplay, err)
+	}
+	return nil
+}
+
+// newEntry creates text entry widget.
+func newEntry(d types.Descriptor, value string) fyne.CanvasObject {
+	var w *widget.Entry
+	switch {
+	case d.Secret:
+		w = widget.NewPasswordEntry()
+	case d.Kind == types.KindMultiline:
+		w = widget.NewMultiLineEntry()
+		w.SetMinRowsVisible(d.Rows)
+	default:
+		w = widget.NewEntry()
+	}
+	w.SetPlaceHolder(d.Placeholder)
+	w.SetText(value)
+	w.Validator = d.Validate
+	return w
+}
+
+// entryValue returns text entry or select entry widget value.
+func entryValue(w fyne.CanvasObject) string {
+	if e, ok := w.(*widget.SelectEntry); ok {
+		return e.Text
+	}
+	return w.(*widget.Entry).Text
+}
+
+// containerEntry returns text entry of the widget container.
+func containerEntry(w fyne.CanvasObject) *widget.Entry {
+	for _, o := range w.(*fyne.Container).Objects {
+		if entry, ok := o.(*widget.Entry); ok {
+			return entry
+		}
+	}
+	return widget.NewEntry()
+}
+
+// newRadioGroup creates radio group widget.
+func newRadioGroup(d types.Descriptor, value string) fyne.CanvasObject {
+	w := widget.NewRadioGroup(d.Options, func(s string) {})
+	w.Selected = value
+	w.Horizontal = d.Horizontal
+	return w
+}
+
+// radioGroupValue returns radio group widget value.
+func radioGroupValue(w fyne.CanvasObject) string {
+	return w.(*widget.RadioGroup).Selected
+}
+
+// newSelect creates select widget.
+func newSelect(d types.Descriptor, value string) fyne.CanvasObject {
+	w := widget.NewSelect(d.Options, func(s string) {})
+	w.Selected = value
+	return w
+}
+
+// selectValue returns select widget value.
+func selectValue(w fyne.CanvasObject) string {
+	return w.(*widget.Select).Selected
+}
+
+// newSelectEntry creates select entry widget.
+func newSelectEntry(d types.Descriptor, value string) fyne.CanvasObject {
+	w := widget.NewSelectEntry(d.Options)
+	w.SetPlaceHolder(d.Placeholder)
+	w.SetText(value)
+	w.Validator = d.Validate
+	return w
+}
+
+// newCheckGroup creates check group widget. The value is JSON array of
+// selected options.
+func newCheckGroup(d types.Descriptor, value string) fyne.CanvasObject {
+	var selected []string
+	json.Unmarshal([]byte(value), &selected)
+	w := widget.NewCheckGroup(d.Options, func(s []string) {})
+	w.Selected = selected
+	w.Horizontal = d.Horizontal
+	return w
+}
+
+// checkGroupValue returns check group widget value encoded as JSON array.
+func checkGroupValue(w fyne.CanvasObject) string {
+	data, _ := json.Marshal(w.(*widget.CheckGroup).Selected)
+	return string(data)
+}
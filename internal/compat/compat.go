@@ -0,0 +1,24 @@
+// Copyright 2024 Kirill Scherba <kirill@scherba.ru>. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package compat connects deprecated types package widget functions with the
+// fyne/form package which creates special field types widgets. The hooks are
+// set by the fyne/form package when it is imported.
+package compat
+
+import (
+	"fyne.io/fyne/v2"
+	"github.com/teonet-go/conf"
+)
+
+var (
+	// NewWidget creates widget of the special field type, returns true if hint
+	// for this field is supported and true if the field type is registered
+	NewWidget func(field *conf.Field[fyne.CanvasObject]) (w fyne.CanvasObject,
+		h, ok bool)
+
+	// WidgetValue returns the field widget value and true if the field type
+	// is registered
+	WidgetValue func(field *conf.Field[fyne.CanvasObject]) (string, bool)
+)
@@ -0,0 +1,963 @@
+// Copyright 2024 Kirill Scherba <kirill@scherba.ru>. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Config helper go package. JSONC module parses JSON with comments and JSON5
+// documents to the concrete syntax tree which keeps the original document
+// text, so edited documents can be saved back with all comments, whitespace,
+// key order and trailing commas preserved.
+
+package conf
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+// NodeKind is a kind of the JSONC syntax tree node.
+type NodeKind int
+
+// JSONC syntax tree node kinds.
+const (
+	NodeNull NodeKind = iota
+	NodeBool
+	NodeNumber
+	NodeString
+	NodeArray
+	NodeObject
+)
+
+// String returns the name of the node kind.
+func (k NodeKind) String() string {
+	switch k {
+	case NodeNull:
+		return "null"
+	case NodeBool:
+		return "bool"
+	case NodeNumber:
+		return "number"
+	case NodeString:
+		return "string"
+	case NodeArray:
+		return "array"
+	case NodeObject:
+		return "object"
+	}
+	return "unknown"
+}
+
+// Node is a value node of the JSONC concrete syntax tree.
+//
+// Start and End are byte offsets of the value text in the document source.
+// Everything between the nodes (whitespace, comments, commas) is not part of
+// any node and is kept untouched when the document is edited.
+type Node struct {
+	Kind     NodeKind
+	Start    int     // Value start offset in the document source
+	End      int     // Value end offset in the document source
+	Key      string  // Member key if the node is an object member value
+	KeyStart int     // Member key start offset in the document source
+	Children []*Node // Array elements or object members
+
+	value any // Decoded scalar value
+}
+
+// Value returns the decoded node value. Numbers are decoded to float64,
+// arrays to []any and objects to map[string]any, the same way the
+// encoding/json package decodes them to an empty interface.
+func (n *Node) Value() any {
+	switch n.Kind {
+	case NodeArray:
+		a := make([]any, len(n.Children))
+		for i, child := range n.Children {
+			a[i] = child.Value()
+		}
+		return a
+	case NodeObject:
+		m := make(map[string]any, len(n.Children))
+		for _, child := range n.Children {
+			m[child.Key] = child.Value()
+		}
+		return m
+	}
+	return n.value
+}
+
+// appendJSON appends the node value encoded as JSON to b. Object members are
+// encoded in the document order.
+func (n *Node) appendJSON(b []byte) ([]byte, error) {
+	switch n.Kind {
+	case NodeArray, NodeObject:
+		start, end := byte('['), byte(']')
+		if n.Kind == NodeObject {
+			start, end = '{', '}'
+		}
+		b = append(b, start)
+		for i, child := range n.Children {
+			if i > 0 {
+				b = append(b, ',')
+			}
+			if n.Kind == NodeObject {
+				key, _ := json.Marshal(child.Key)
+				b = append(append(b, key...), ':')
+			}
+			var err error
+			if b, err = child.appendJSON(b); err != nil {
+				return nil, err
+			}
+		}
+		return append(b, end), nil
+	}
+	data, err := json.Marshal(n.value)
+	return append(b, data...), err
+}
+
+// nonFinite returns the first Infinity or NaN number node of the node tree or
+// nil if there are no such numbers.
+func nonFinite(n *Node) *Node {
+	if f, ok := n.value.(float64); ok && (math.IsInf(f, 0) || math.IsNaN(f)) {
+		return n
+	}
+	for _, child := range n.Children {
+		if found := nonFinite(child); found != nil {
+			return found
+		}
+	}
+	return nil
+}
+
+// Member returns object member node by key or nil if the node is not an object
+// or does not contain the key.
+func (n *Node) Member(key string) *Node {
+	if n.Kind != NodeObject {
+		return nil
+	}
+	for _, child := range n.Children {
+		if child.Key == key {
+			return child
+		}
+	}
+	return nil
+}
+
+// Document is a parsed JSONC or JSON5 document.
+type Document struct {
+	src  []byte
+	root *Node
+}
+
+// ParseJSONC parses JSON, JSON with comments or JSON5 document.
+//
+// Besides the plain JSON the parser accepts line and block comments, trailing
+// commas, unquoted member keys, single quoted strings, hexadecimal numbers,
+// numbers with leading or trailing decimal point or plus sign, Infinity and
+// NaN. Infinity and NaN are available with Value but can't be decoded with
+// Decode.
+func ParseJSONC(data []byte) (doc *Document, err error) {
+	p := &parser{src: data}
+	root, err := p.parse()
+	if err != nil {
+		return
+	}
+	doc = &Document{src: data, root: root}
+	return
+}
+
+// Bytes returns the document source with all edits applied.
+func (d *Document) Bytes() []byte { return d.src }
+
+// Root returns the root node of the document.
+func (d *Document) Root() *Node { return d.root }
+
+// Value returns the decoded document value.
+func (d *Document) Value() any { return d.root.Value() }
+
+// Decode stores the document value in the value pointed to by v using the
+// encoding/json unmarshal rules. Object members are decoded in the document
+// order, so values implementing json.Unmarshaler may keep it. Infinity and NaN
+// can't be decoded and are returned as an error with their line and column.
+func (d *Document) Decode(v any) error {
+	if n := nonFinite(d.root); n != nil {
+		return errorAt(d.src, n.Start, "%s can't be decoded",
+			d.src[n.Start:n.End])
+	}
+	data, err := d.root.appendJSON(nil)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
+
+// Get returns the decoded value of the root object member by key and true if
+// it exists.
+func (d *Document) Get(key string) (value any, ok bool) {
+	n := d.root.Member(key)
+	if n == nil {
+		return
+	}
+	return n.Value(), true
+}
+
+// Set sets the root object member value by key. It is SetPath with the
+// pointer to the root object member.
+func (d *Document) Set(key string, value any) error {
+	return d.set([]string{key}, value)
+}
+
+// SetPath sets the value by JSON Pointer (RFC 6901) path. The value is encoded
+// with the encoding/json package. The existing value text is replaced in
+// place, a new object member or a new array element ("-" or the array length
+// last segment) is added after the last one. All other document text stays
+// unchanged.
+func (d *Document) SetPath(path string, value any) error {
+	segs, err := parsePointer(path)
+	if err != nil {
+		return err
+	}
+	return d.set(segs, value)
+}
+
+// Delete removes the root object member by key. It is DeletePath with the
+// pointer to the root object member.
+func (d *Document) Delete(key string) error {
+	return d.delete([]string{key})
+}
+
+// DeletePath removes the object member or the array element by JSON Pointer
+// (RFC 6901) path. The member line is removed with its trailing comment when
+// the member is the only value on the line. All other document text stays
+// unchanged.
+func (d *Document) DeletePath(path string) error {
+	segs, err := parsePointer(path)
+	if err != nil {
+		return err
+	}
+	return d.delete(segs)
+}
+
+// update patches the document value by path segments to the new value node n
+// parsed from src. Object members and array elements of the same length are
+// patched recursively, so only changed values are replaced and comments and
+// formatting of nested values are preserved. Object members missing in n are
+// removed, objects with reordered members are replaced.
+func (d *Document) update(segs []string, n *Node, src []byte) error {
+	old := d.lookup(segs)
+	switch {
+	case old == nil:
+	case old.Kind == NodeObject && n.Kind == NodeObject && !sameOrder(old, n):
+		// Reordered members are replaced with the whole object
+	case old.Kind == NodeObject && n.Kind == NodeObject:
+		var removed []string
+		for _, child := range old.Children {
+			if n.Member(child.Key) == nil {
+				removed = append(removed, child.Key)
+			}
+		}
+		for _, key := range removed {
+			if err := d.delete(append(segs[:len(segs):len(segs)], key)); err != nil {
+				return err
+			}
+		}
+		for _, child := range n.Children {
+			err := d.update(append(segs[:len(segs):len(segs)], child.Key), child,
+				src)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	case old.Kind == NodeArray && n.Kind == NodeArray &&
+		len(old.Children) == len(n.Children):
+		for i, child := range n.Children {
+			err := d.update(append(segs[:len(segs):len(segs)], strconv.Itoa(i)),
+				child, src)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	case reflect.DeepEqual(old.Value(), n.Value()):
+		return nil
+	}
+	return d.set(segs, json.RawMessage(src[n.Start:n.End]))
+}
+
+// sameOrder checks if the members of objects a and b which exist in both
+// objects are in the same order.
+func sameOrder(a, b *Node) bool {
+	var keys []string
+	for _, child := range a.Children {
+		if b.Member(child.Key) != nil {
+			keys = append(keys, child.Key)
+		}
+	}
+	i := 0
+	for _, child := range b.Children {
+		if a.Member(child.Key) == nil {
+			continue
+		}
+		if keys[i] != child.Key {
+			return false
+		}
+		i++
+	}
+	return true
+}
+
+// lookup returns the node by path segments or nil if it does not exist.
+func (d *Document) lookup(segs []string) *Node {
+	n := d.root
+	for _, seg := range segs {
+		switch n.Kind {
+		case NodeObject:
+			n = n.Member(seg)
+		case NodeArray:
+			i, err := strconv.Atoi(seg)
+			if err != nil || i < 0 || i >= len(n.Children) {
+				return nil
+			}
+			n = n.Children[i]
+		default:
+			return nil
+		}
+		if n == nil {
+			return nil
+		}
+	}
+	return n
+}
+
+// set sets the value by path segments.
+func (d *Document) set(segs []string, value any) (err error) {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return
+	}
+
+	// Replace existing value
+	if n := d.lookup(segs); n != nil {
+		return d.splice(edit{n.Start, n.End, data})
+	}
+
+	// Add new object member or array element
+	parent := d.lookup(segs[:len(segs)-1])
+	key := segs[len(segs)-1]
+	switch {
+	case parent == nil:
+		err = fmt.Errorf("parent not found")
+	case parent.Kind == NodeObject:
+		name, _ := json.Marshal(key)
+		return d.insert(parent, append(append(name, ": "...), data...))
+	case parent.Kind == NodeArray &&
+		(key == "-" || key == strconv.Itoa(len(parent.Children))):
+		return d.insert(parent, data)
+	case parent.Kind == NodeArray:
+		err = fmt.Errorf("array index %s out of range", key)
+	default:
+		err = fmt.Errorf("parent is %s", parent.Kind)
+	}
+	return pathError(segs, err)
+}
+
+// insert adds the member or element text after the last child of the parent
+// object or array. The text is added on a new line with the last child
+// indent after its comma and trailing comment if the last child ends its
+// line, otherwise it is added on the same line.
+func (d *Document) insert(parent *Node, text []byte) error {
+	if len(parent.Children) == 0 {
+		return d.splice(edit{parent.Start + 1, parent.Start + 1, text})
+	}
+	last := parent.Children[len(parent.Children)-1]
+	start := last.Start
+	if parent.Kind == NodeObject {
+		start = last.KeyStart
+	}
+	comma := d.commaAfter(last.End)
+
+	// The last child is not the last value on its line
+	pos := last.End
+	if comma >= 0 {
+		pos = comma + 1
+	}
+	eol, ok := d.lineEnd(pos)
+	if !ok {
+		if comma >= 0 {
+			return d.splice(edit{pos, pos, append(append([]byte(" "), text...), ',')})
+		}
+		return d.splice(edit{pos, pos, append([]byte(", "), text...)})
+	}
+
+	// Add new line after the last child line keeping trailing comma style
+	line := append([]byte("\n"+d.indent(start)), text...)
+	if comma >= 0 {
+		return d.splice(edit{eol, eol, append(line, ',')})
+	}
+	return d.splice(edit{last.End, last.End, []byte(",")}, edit{eol, eol, line})
+}
+
+// delete removes the object member or the array element by path segments.
+func (d *Document) delete(segs []string) (err error) {
+	if len(segs) == 0 {
+		return fmt.Errorf("can't delete document root")
+	}
+
+	// Find the child index in the parent
+	parent, i := d.lookup(segs[:len(segs)-1]), -1
+	if parent != nil {
+		key := segs[len(segs)-1]
+		for j, child := range parent.Children {
+			if (parent.Kind == NodeObject && child.Key == key) ||
+				(parent.Kind == NodeArray && strconv.Itoa(j) == key) {
+				i = j
+				break
+			}
+		}
+	}
+	if i < 0 {
+		return pathError(segs, fmt.Errorf("value not found"))
+	}
+	n := parent.Children[i]
+	start, end := n.Start, n.End
+	if parent.Kind == NodeObject {
+		start = n.KeyStart
+	}
+	comma := d.commaAfter(end)
+	if comma >= 0 {
+		end = comma + 1
+	}
+
+	// Remove the whole line if the child is the only value on it, otherwise
+	// remove the child text with its comma. The comma of the previous child
+	// is removed too if the removed child was the last one without comma.
+	var prev = -1
+	if comma < 0 && i > 0 {
+		prev = d.commaAfter(parent.Children[i-1].End)
+	}
+	lineStart := start
+	for lineStart > 0 && (d.src[lineStart-1] == ' ' || d.src[lineStart-1] == '\t') {
+		lineStart--
+	}
+	eol, ok := d.lineEnd(end)
+	switch {
+	case ok && (lineStart == 0 || d.src[lineStart-1] == '\n'):
+		if eol < len(d.src) && d.src[eol] == '\r' {
+			eol++
+		}
+		if eol < len(d.src) && d.src[eol] == '\n' {
+			eol++
+		}
+		edits := []edit{{lineStart, eol, nil}}
+		if prev >= 0 {
+			edits = append(edits, edit{prev, prev + 1, nil})
+		}
+		return d.splice(edits...)
+	case prev >= 0:
+		start = prev
+	case comma >= 0:
+		for end < len(d.src) && (d.src[end] == ' ' || d.src[end] == '\t') {
+			end++
+		}
+	}
+	return d.splice(edit{start, end, nil})
+}
+
+// commaAfter returns the offset of the comma following the value which ends
+// at the offset or -1 if there is no comma.
+func (d *Document) commaAfter(offset int) int {
+	p := &parser{src: d.src, pos: offset}
+	if p.skip() != nil || p.pos >= len(p.src) || p.src[p.pos] != ',' {
+		return -1
+	}
+	return p.pos
+}
+
+// lineEnd returns the offset of the line end and true if there are only
+// whitespace and comments between the offset and the line end.
+func (d *Document) lineEnd(offset int) (int, bool) {
+	for p := offset; ; {
+		switch {
+		case p >= len(d.src) || d.src[p] == '\n' || d.src[p] == '\r':
+			return p, true
+		case d.src[p] == ' ' || d.src[p] == '\t':
+			p++
+		case bytes.HasPrefix(d.src[p:], []byte("//")):
+			for p < len(d.src) && d.src[p] != '\n' && d.src[p] != '\r' {
+				p++
+			}
+		case bytes.HasPrefix(d.src[p:], []byte("/*")):
+			end := bytes.Index(d.src[p+2:], []byte("*/"))
+			if end < 0 {
+				return p, false
+			}
+			p += end + 4
+		default:
+			return p, false
+		}
+	}
+}
+
+// edit is a replacement of the document source text between start and end
+// offsets with data.
+type edit struct {
+	start, end int
+	data       []byte
+}
+
+// splice applies not overlapping edits to the document source and parses the
+// document again to update node offsets.
+func (d *Document) splice(edits ...edit) (err error) {
+	sort.Slice(edits, func(i, j int) bool {
+		return edits[i].start > edits[j].start
+	})
+	src := append([]byte(nil), d.src...)
+	for _, e := range edits {
+		tail := append([]byte(nil), src[e.end:]...)
+		src = append(append(src[:e.start], e.data...), tail...)
+	}
+
+	p := &parser{src: src}
+	root, err := p.parse()
+	if err != nil {
+		return
+	}
+	d.src, d.root = src, root
+	return
+}
+
+// indent returns the whitespace between the line start and the offset.
+func (d *Document) indent(offset int) string {
+	start := offset
+	for start > 0 && (d.src[start-1] == ' ' || d.src[start-1] == '\t') {
+		start--
+	}
+	return string(d.src[start:offset])
+}
+
+// isDocument checks if the given object is a pointer to a JSONC document.
+func isDocument(o any) bool {
+	_, ok := o.(*Document)
+	return ok
+}
+
+// parser is the JSONC recursive descent parser.
+type parser struct {
+	src []byte
+	pos int
+}
+
+// parse parses the whole document.
+func (p *parser) parse() (n *Node, err error) {
+	if err = p.skip(); err != nil {
+		return
+	}
+	if n, err = p.value(); err != nil {
+		return
+	}
+	if err = p.skip(); err != nil {
+		return
+	}
+	if p.pos < len(p.src) {
+		err = p.errorf("unexpected %q after top-level value", p.peek())
+	}
+	return
+}
+
+// value parses any value at the current position.
+func (p *parser) value() (n *Node, err error) {
+	if p.pos >= len(p.src) {
+		return nil, p.errorf("unexpected end of input")
+	}
+	start := p.pos
+	switch c := p.src[p.pos]; {
+	case c == '{':
+		return p.object()
+	case c == '[':
+		return p.array()
+	case c == '"' || c == '\'':
+		var s string
+		if s, err = p.string(); err != nil {
+			return
+		}
+		n = &Node{Kind: NodeString, value: s}
+	case c == '-' || c == '+' || c == '.' || (c >= '0' && c <= '9'):
+		var f float64
+		if f, err = p.number(); err != nil {
+			return
+		}
+		n = &Node{Kind: NodeNumber, value: f}
+	default:
+		switch word := p.ident(); word {
+		case "true", "false":
+			n = &Node{Kind: NodeBool, value: word == "true"}
+		case "null":
+			n = &Node{Kind: NodeNull}
+		case "Infinity":
+			n = &Node{Kind: NodeNumber, value: math.Inf(1)}
+		case "NaN":
+			n = &Node{Kind: NodeNumber, value: math.NaN()}
+		default:
+			p.pos = start
+			return nil, p.errorf("unexpected %q", p.peek())
+		}
+	}
+	n.Start, n.End = start, p.pos
+	return
+}
+
+// object parses object.
+func (p *parser) object() (n *Node, err error) {
+	n = &Node{Kind: NodeObject, Start: p.pos}
+	p.pos++ // {
+	for {
+		if err = p.skip(); err != nil {
+			return
+		}
+		if p.pos < len(p.src) && p.src[p.pos] == '}' {
+			break
+		}
+
+		// Member key
+		keyStart := p.pos
+		var key string
+		switch {
+		case p.pos >= len(p.src):
+			return nil, p.errorf("unexpected end of input in object")
+		case p.src[p.pos] == '"' || p.src[p.pos] == '\'':
+			key, err = p.string()
+		default:
+			if key = p.ident(); key == "" {
+				err = p.errorf("invalid object key %q", p.peek())
+			}
+		}
+		if err != nil {
+			return
+		}
+
+		// Colon
+		if err = p.skip(); err != nil {
+			return
+		}
+		if err = p.expect(':'); err != nil {
+			return
+		}
+		if err = p.skip(); err != nil {
+			return
+		}
+
+		// Member value
+		var child *Node
+		if child, err = p.value(); err != nil {
+			return
+		}
+		child.Key, child.KeyStart = key, keyStart
+		n.Children = append(n.Children, child)
+
+		// Comma or end of object
+		if err = p.skip(); err != nil {
+			return
+		}
+		if p.pos < len(p.src) && p.src[p.pos] == ',' {
+			p.pos++
+			continue
+		}
+		if p.pos >= len(p.src) || p.src[p.pos] != '}' {
+			return nil, p.expect('}')
+		}
+		break
+	}
+	p.pos++ // }
+	n.End = p.pos
+	return
+}
+
+// array parses array.
+func (p *parser) array() (n *Node, err error) {
+	n = &Node{Kind: NodeArray, Start: p.pos}
+	p.pos++ // [
+	for {
+		if err = p.skip(); err != nil {
+			return
+		}
+		if p.pos < len(p.src) && p.src[p.pos] == ']' {
+			break
+		}
+
+		// Array element
+		var child *Node
+		if child, err = p.value(); err != nil {
+			return
+		}
+		n.Children = append(n.Children, child)
+
+		// Comma or end of array
+		if err = p.skip(); err != nil {
+			return
+		}
+		if p.pos < len(p.src) && p.src[p.pos] == ',' {
+			p.pos++
+			continue
+		}
+		if p.pos >= len(p.src) || p.src[p.pos] != ']' {
+			return nil, p.expect(']')
+		}
+		break
+	}
+	p.pos++ // ]
+	n.End = p.pos
+	return
+}
+
+// string parses double or single quoted string.
+func (p *parser) string() (s string, err error) {
+	quote := p.src[p.pos]
+	p.pos++
+	var b strings.Builder
+	for {
+		if p.pos >= len(p.src) {
+			return "", p.errorf("unterminated string")
+		}
+		c := p.src[p.pos]
+		switch {
+		case c == quote:
+			p.pos++
+			return b.String(), nil
+		case c == '\n' || c == '\r':
+			return "", p.errorf("newline in string")
+		case c == '\\':
+			if err = p.escape(&b); err != nil {
+				return
+			}
+		default:
+			r, size := utf8.DecodeRune(p.src[p.pos:])
+			b.WriteRune(r)
+			p.pos += size
+		}
+	}
+}
+
+// escape parses string escape sequence and writes it to the builder.
+func (p *parser) escape(b *strings.Builder) (err error) {
+	p.pos++ // \
+	if p.pos >= len(p.src) {
+		return p.errorf("unterminated string")
+	}
+	c := p.src[p.pos]
+	p.pos++
+	switch c {
+	case 'b':
+		b.WriteByte('\b')
+	case 'f':
+		b.WriteByte('\f')
+	case 'n':
+		b.WriteByte('\n')
+	case 'r':
+		b.WriteByte('\r')
+	case 't':
+		b.WriteByte('\t')
+	case 'v':
+		b.WriteByte('\v')
+	case '0':
+		b.WriteByte(0)
+	case '\n':
+		// Line continuation
+	case '\r':
+		// Line continuation
+		if p.pos < len(p.src) && p.src[p.pos] == '\n' {
+			p.pos++
+		}
+	case 'x':
+		var r rune
+		if r, err = p.hex(2); err != nil {
+			return
+		}
+		b.WriteRune(r)
+	case 'u':
+		var r rune
+		if r, err = p.hex(4); err != nil {
+			return
+		}
+		// Surrogate pair
+		if utf16.IsSurrogate(r) && p.pos+1 < len(p.src) &&
+			p.src[p.pos] == '\\' && p.src[p.pos+1] == 'u' {
+			pos := p.pos
+			p.pos += 2
+			var r2 rune
+			if r2, err = p.hex(4); err != nil {
+				return
+			}
+			if dec := utf16.DecodeRune(r, r2); dec != utf8.RuneError {
+				r = dec
+			} else {
+				p.pos = pos
+			}
+		}
+		b.WriteRune(r)
+	default:
+		b.WriteByte(c)
+	}
+	return
+}
+
+// hex parses n hexadecimal digits.
+func (p *parser) hex(n int) (r rune, err error) {
+	if p.pos+n > len(p.src) {
+		return 0, p.errorf("invalid escape sequence")
+	}
+	v, err := strconv.ParseUint(string(p.src[p.pos:p.pos+n]), 16, 32)
+	if err != nil {
+		return 0, p.errorf("invalid escape sequence")
+	}
+	p.pos += n
+	return rune(v), nil
+}
+
+// number parses number.
+func (p *parser) number() (f float64, err error) {
+	start := p.pos
+	sign := 1.0
+	if c := p.src[p.pos]; c == '-' || c == '+' {
+		if c == '-' {
+			sign = -1
+		}
+		p.pos++
+	}
+
+	// Infinity and NaN with sign
+	if word := p.ident(); word != "" {
+		switch word {
+		case "Infinity":
+			return math.Inf(int(sign)), nil
+		case "NaN":
+			return math.NaN(), nil
+		}
+		p.pos = start
+		return 0, p.errorf("invalid number")
+	}
+
+	// Hexadecimal number
+	if p.pos+1 < len(p.src) && p.src[p.pos] == '0' &&
+		(p.src[p.pos+1] == 'x' || p.src[p.pos+1] == 'X') {
+		p.pos += 2
+		digits := p.pos
+		for p.pos < len(p.src) && isHexDigit(p.src[p.pos]) {
+			p.pos++
+		}
+		var u uint64
+		u, err = strconv.ParseUint(string(p.src[digits:p.pos]), 16, 64)
+		if err != nil {
+			p.pos = start
+			return 0, p.errorf("invalid hexadecimal number")
+		}
+		return sign * float64(u), nil
+	}
+
+	// Decimal number
+	digits := p.pos
+	for p.pos < len(p.src) {
+		c := p.src[p.pos]
+		if (c >= '0' && c <= '9') || c == '.' || c == 'e' || c == 'E' ||
+			((c == '-' || c == '+') &&
+				(p.src[p.pos-1] == 'e' || p.src[p.pos-1] == 'E')) {
+			p.pos++
+			continue
+		}
+		break
+	}
+	f, err = strconv.ParseFloat(string(p.src[digits:p.pos]), 64)
+	if err != nil {
+		p.pos = start
+		return 0, p.errorf("invalid number")
+	}
+	return sign * f, nil
+}
+
+// ident parses identifier: unquoted key or literal.
+func (p *parser) ident() string {
+	start := p.pos
+	for p.pos < len(p.src) {
+		r, size := utf8.DecodeRune(p.src[p.pos:])
+		if r == '_' || r == '$' || unicode.IsLetter(r) ||
+			(p.pos > start && unicode.IsDigit(r)) {
+			p.pos += size
+			continue
+		}
+		break
+	}
+	return string(p.src[start:p.pos])
+}
+
+// skip skips whitespace and comments.
+func (p *parser) skip() error {
+	for p.pos < len(p.src) {
+		r, size := utf8.DecodeRune(p.src[p.pos:])
+		switch {
+		case unicode.IsSpace(r) || r == '\uFEFF':
+			p.pos += size
+		case r == '/' && p.pos+1 < len(p.src) && p.src[p.pos+1] == '/':
+			for p.pos < len(p.src) && p.src[p.pos] != '\n' {
+				p.pos++
+			}
+		case r == '/' && p.pos+1 < len(p.src) && p.src[p.pos+1] == '*':
+			end := strings.Index(string(p.src[p.pos+2:]), "*/")
+			if end < 0 {
+				return p.errorf("unterminated comment")
+			}
+			p.pos += end + 4
+		default:
+			return nil
+		}
+	}
+	return nil
+}
+
+// expect checks that the current character is c and skips it.
+func (p *parser) expect(c byte) error {
+	if p.pos >= len(p.src) {
+		return p.errorf("unexpected end of input, expected %q", c)
+	}
+	if p.src[p.pos] != c {
+		return p.errorf("unexpected %q, expected %q", p.peek(), c)
+	}
+	p.pos++
+	return nil
+}
+
+// peek returns the rune at the current position.
+func (p *parser) peek() rune {
+	r, _ := utf8.DecodeRune(p.src[p.pos:])
+	return r
+}
+
+// errorf returns an error with the current line and column.
+func (p *parser) errorf(format string, args ...any) error {
+	return errorAt(p.src, p.pos, format, args...)
+}
+
+// errorAt returns an error with the line and column of the source offset.
+func errorAt(src []byte, offset int, format string, args ...any) error {
+	line, col := 1, 1
+	for _, c := range src[:offset] {
+		if c == '\n' {
+			line, col = line+1, 1
+			continue
+		}
+		col++
+	}
+	return fmt.Errorf("jsonc: line %d, column %d: %s", line, col,
+		fmt.Sprintf(format, args...))
+}
+
+// isHexDigit checks if c is a hexadecimal digit.
+func isHexDigit(c byte) bool {
+	return (c >= '0' && c <= '9') || (c >= 'a' && c <= 'f') ||
+		(c >= 'A' && c <= 'F')
+}
@@ -0,0 +1,197 @@
+package conf
+
+import (
+	"encoding/json"
+	"math"
+	"strings"
+	"testing"
+)
+
+const jsoncData = `// Application config
+{
+  /* Display name */
+  "name": "John Smith", // trailing comment
+  age: 57,
+  'hex': 0xFF,
+  "ratio": .5,
+  "list": [1, 2, 3,],
+  "on": false,
+}
+`
+
+func TestParseJSONC(t *testing.T) {
+
+	doc, err := ParseJSONC([]byte(jsoncData))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := map[string]any{
+		"name":  "John Smith",
+		"age":   57.0,
+		"hex":   255.0,
+		"ratio": 0.5,
+		"on":    false,
+	}
+	for key, want := range tests {
+		if got, ok := doc.Get(key); !ok || got != want {
+			t.Fatalf("%s: got %v, want %v", key, got, want)
+		}
+	}
+
+	if _, err = ParseJSONC([]byte(`{"a": 1 "b": 2}`)); err == nil {
+		t.Fatal("missing comma should be an error")
+	}
+
+	doc, err = ParseJSONC([]byte(`[-Infinity, NaN]`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	a := doc.Value().([]any)
+	if !math.IsInf(a[0].(float64), -1) || !math.IsNaN(a[1].(float64)) {
+		t.Fatalf("wrong Infinity or NaN: %v", a)
+	}
+}
+
+func TestDocumentSetValue(t *testing.T) {
+
+	doc, err := ParseJSONC([]byte(jsoncData))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	fields := GetFields(doc, func(field *Field[any]) {})
+	if len(fields) != 6 || fields[0].Name != "name" || fields[5].Name != "on" {
+		t.Fatal("fields should be in the document order")
+	}
+
+	for _, field := range fields {
+		switch field.Name {
+		case "name":
+			err = field.SetValue(doc, "Jane Smith")
+		case "on":
+			err = field.SetValue(doc, "true")
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err = doc.Set("email", "jane@example.com"); err != nil {
+		t.Fatal(err)
+	}
+
+	const want = `// Application config
+{
+  /* Display name */
+  "name": "Jane Smith", // trailing comment
+  age: 57,
+  'hex': 0xFF,
+  "ratio": .5,
+  "list": [1, 2, 3,],
+  "on": true,
+  "email": "jane@example.com",
+}
+`
+	if got := string(doc.Bytes()); got != want {
+		t.Fatalf("got:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestDocumentDecodeOrder(t *testing.T) {
+
+	doc, err := ParseJSONC([]byte(`{"m": {"b": 1, /* c */ "a": [true, null]}}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var v struct {
+		M json.RawMessage `json:"m"`
+	}
+	if err = doc.Decode(&v); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := string(v.M), `{"b":1,"a":[true,null]}`; got != want {
+		t.Fatalf("got %s, want %s", got, want)
+	}
+}
+
+func TestDocumentDecodeNaN(t *testing.T) {
+
+	doc, err := ParseJSONC([]byte("{\n  \"a\": [1, -Infinity]\n}"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var v struct{ A []float64 }
+	err = doc.Decode(&v)
+	if err == nil || !strings.Contains(err.Error(), "line 2, column 12") ||
+		!strings.Contains(err.Error(), "-Infinity") {
+		t.Fatalf("got error %v, want -Infinity position", err)
+	}
+}
+
+func TestDocumentSetPath(t *testing.T) {
+
+	const data = `{
+  "db": {
+    // host comment
+    "host": "a",
+    "port": 1
+  },
+  "gone": 2 // last
+}
+`
+	doc, err := ParseJSONC([]byte(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = doc.SetPath("/db/port", 2); err != nil {
+		t.Fatal(err)
+	}
+	if err = doc.Set("new", "v"); err != nil {
+		t.Fatal(err)
+	}
+	if err = doc.SetPath("/list/0", 1); err == nil {
+		t.Fatal("missing parent should be an error")
+	}
+
+	const want = `{
+  "db": {
+    // host comment
+    "host": "a",
+    "port": 2
+  },
+  "gone": 2, // last
+  "new": "v"
+}
+`
+	if got := string(doc.Bytes()); got != want {
+		t.Fatalf("got:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestDocumentDelete(t *testing.T) {
+
+	for _, test := range []struct {
+		data, path, want string
+	}{
+		{"{\n  \"a\": 1, // a\n  \"b\": 2 // b\n}", "/b",
+			"{\n  \"a\": 1 // a\n}"},
+		{"{\n  \"a\": 1, // a\n  \"b\": 2,\n}", "/a", "{\n  \"b\": 2,\n}"},
+		{`{"a": 1, "b": 2}`, "/a", `{"b": 2}`},
+		{`{"a": 1, "b": 2}`, "/b", `{"a": 1}`},
+		{`{"a": [1, 2, 3]}`, "/a/1", `{"a": [1, 3]}`},
+		{`{"a": 1}`, "/a", `{}`},
+	} {
+		doc, err := ParseJSONC([]byte(test.data))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err = doc.DeletePath(test.path); err != nil {
+			t.Fatal(err)
+		}
+		if got := string(doc.Bytes()); got != test.want {
+			t.Fatalf("delete %s from %s: got %s, want %s", test.path,
+				test.data, got, test.want)
+		}
+	}
+}
@@ -0,0 +1,146 @@
+// Copyright 2024 Kirill Scherba <kirill@scherba.ru>. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Config helper go package. Lock module provides advisory locking of config
+// files used to serialize config file readers and writers in different
+// processes.
+
+package conf
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"strconv"
+	"time"
+)
+
+// DefaultLockTimeout is the lock wait timeout used by File when its
+// LockTimeout is zero.
+const DefaultLockTimeout = 10 * time.Second
+
+// StaleLockTimeout is the age of the fallback lock file after which it is
+// treated as left by a crashed process and removed.
+const StaleLockTimeout = time.Minute
+
+// lockRetry is the interval between lock attempts.
+const lockRetry = 20 * time.Millisecond
+
+// Lock is an advisory lock of a config file.
+//
+// The lock uses flock on the Path.lock file where it is supported. On other
+// systems or file systems the Path.lck lock file is created exclusively and
+// removed on Unlock. The shared lock of a config file in a read-only
+// directory, where the lock file can't be created, does not lock anything, so
+// such configs are read without a lock.
+type Lock struct {
+	file     *os.File // Locked file
+	fallback bool     // Lock file is used instead of flock
+}
+
+// LockFile locks the config file path and returns the lock. The shared lock
+// may be held by several readers, the exclusive lock is held by one writer.
+// LockFile waits for the lock until the context is done.
+func LockFile(ctx context.Context, path string, shared bool) (l *Lock, err error) {
+	for {
+		l, err = tryLock(path, shared)
+		if err == nil {
+			return
+		}
+		if !errors.Is(err, errLocked) {
+			return nil, fmt.Errorf("can't lock %s: %w", path, err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, fmt.Errorf("can't lock %s: %w", path, ctx.Err())
+		case <-time.After(lockRetry):
+		}
+	}
+}
+
+// Unlock releases the lock.
+func (l *Lock) Unlock() (err error) {
+	if l.file == nil {
+		return
+	}
+	if l.fallback {
+		err = os.Remove(l.file.Name())
+		l.file.Close()
+		return
+	}
+	if err = funlock(l.file); err != nil {
+		l.file.Close()
+		return
+	}
+	return l.file.Close()
+}
+
+// errLocked is returned by tryLock when the file is locked by someone else.
+var errLocked = errors.New("file is locked")
+
+// tryLock tries to lock the config file path without waiting. It returns
+// errLocked if the file is locked by someone else.
+func tryLock(path string, shared bool) (l *Lock, err error) {
+
+	// Lock with flock
+	f, err := openLockFile(path+".lock", shared)
+	if shared && isReadOnly(err) {
+		return &Lock{}, nil
+	}
+	if err != nil {
+		return
+	}
+	err = flock(f, shared)
+	if err == nil {
+		return &Lock{file: f}, nil
+	}
+	f.Close()
+	if !errors.Is(err, errors.ErrUnsupported) {
+		return
+	}
+
+	// Lock with lock file
+	name := path + ".lck"
+	f, err = os.OpenFile(name, os.O_RDWR|os.O_CREATE|os.O_EXCL, 0644)
+	if errors.Is(err, fs.ErrExist) {
+		if fi, err := os.Stat(name); err == nil &&
+			time.Since(fi.ModTime()) > StaleLockTimeout {
+			os.Remove(name)
+		}
+		return nil, errLocked
+	}
+	if shared && isReadOnly(err) {
+		return &Lock{}, nil
+	}
+	if err != nil {
+		return
+	}
+	f.WriteString(strconv.Itoa(os.Getpid()))
+	return &Lock{file: f, fallback: true}, nil
+}
+
+// openLockFile opens or creates the flock lock file. The existing lock file of
+// the shared lock is opened read-only, so it may be in a read-only directory.
+func openLockFile(name string, shared bool) (*os.File, error) {
+	if shared {
+		if f, err := os.Open(name); err == nil {
+			return f, nil
+		}
+	}
+	return os.OpenFile(name, os.O_RDWR|os.O_CREATE, 0644)
+}
+
+// lock locks the config file for the File operation.
+func (f *File) lock(ctx context.Context, shared bool) (*Lock, error) {
+	timeout := f.LockTimeout
+	if timeout == 0 {
+		timeout = DefaultLockTimeout
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	return LockFile(ctx, f.Path, shared)
+}
@@ -0,0 +1,23 @@
+// Copyright 2024 Kirill Scherba <kirill@scherba.ru>. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build !unix
+
+package conf
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+)
+
+// flock is not supported on this system, the lock file is used instead.
+func flock(f *os.File, shared bool) error { return errors.ErrUnsupported }
+
+// funlock is not supported on this system.
+func funlock(f *os.File) error { return errors.ErrUnsupported }
+
+// isReadOnly checks if the error is returned because the file can't be
+// created in a read-only directory.
+func isReadOnly(err error) bool { return errors.Is(err, fs.ErrPermission) }
@@ -0,0 +1,85 @@
+package conf
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestLockFile(t *testing.T) {
+
+	path := filepath.Join(t.TempDir(), "config.json")
+	ctx := context.Background()
+
+	// Shared locks
+	l1, err := LockFile(ctx, path, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	l2, err := LockFile(ctx, path, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Writer waits for readers
+	file := NewFile(path)
+	file.LockTimeout = 50 * time.Millisecond
+	err = file.Save(map[string]any{"name": "John"})
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("save should wait for the lock, got %v", err)
+	}
+
+	// Cancelled context
+	cctx, cancel := context.WithCancel(ctx)
+	cancel()
+	if _, err = LockFile(cctx, path, false); !errors.Is(err, context.Canceled) {
+		t.Fatalf("lock should be cancelled, got %v", err)
+	}
+
+	// Writer gets the lock after readers unlock it
+	time.AfterFunc(20*time.Millisecond, func() {
+		l1.Unlock()
+		l2.Unlock()
+	})
+	file.LockTimeout = time.Second
+	if err = file.SaveContext(ctx, map[string]any{"name": "John"}); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestLockReadOnly(t *testing.T) {
+
+	if os.Geteuid() == 0 {
+		t.Skip("root can create files in read-only directories")
+	}
+	dir := t.TempDir()
+	path := filepath.Join(dir, "config.json")
+	if err := os.WriteFile(path, []byte(`{"name": "John"}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chmod(dir, 0555); err != nil {
+		t.Fatal(err)
+	}
+	defer os.Chmod(dir, 0755)
+
+	// Readers load the config without the lock file
+	var c map[string]any
+	if err := Load(path, &c); err != nil {
+		t.Fatal(err)
+	}
+	if c["name"] != "John" {
+		t.Fatalf("wrong loaded config: %v", c)
+	}
+	if _, err := os.Stat(path + ".lock"); !errors.Is(err, fs.ErrNotExist) {
+		t.Fatal("lock file should not be created")
+	}
+
+	// Writers still need the lock
+	if err := Save(path, c); err == nil {
+		t.Fatal("save to read-only directory should be an error")
+	}
+}
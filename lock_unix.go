@@ -0,0 +1,45 @@
+// Copyright 2024 Kirill Scherba <kirill@scherba.ru>. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build unix
+
+package conf
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"syscall"
+)
+
+// flock locks the file with flock without waiting. It returns errLocked if the
+// file is locked by someone else and errors.ErrUnsupported if the file system
+// does not support flock.
+func flock(f *os.File, shared bool) error {
+	how := syscall.LOCK_EX
+	if shared {
+		how = syscall.LOCK_SH
+	}
+	err := syscall.Flock(int(f.Fd()), how|syscall.LOCK_NB)
+	switch {
+	case err == nil:
+		return nil
+	case errors.Is(err, syscall.EWOULDBLOCK) || errors.Is(err, syscall.EINTR):
+		return errLocked
+	case errors.Is(err, syscall.ENOLCK) || errors.Is(err, syscall.EOPNOTSUPP):
+		return errors.ErrUnsupported
+	}
+	return err
+}
+
+// funlock unlocks the file locked with flock.
+func funlock(f *os.File) error {
+	return syscall.Flock(int(f.Fd()), syscall.LOCK_UN)
+}
+
+// isReadOnly checks if the error is returned because the file can't be
+// created in a read-only directory or file system.
+func isReadOnly(err error) bool {
+	return errors.Is(err, fs.ErrPermission) || errors.Is(err, syscall.EROFS)
+}
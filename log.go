@@ -0,0 +1,122 @@
+// Copyright 2024 Kirill Scherba <kirill@scherba.ru>. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Config helper go package. Log module writes diagnostics to the structured
+// logger.
+
+package conf
+
+import (
+	"context"
+	"log/slog"
+	"reflect"
+	"sync/atomic"
+)
+
+// LevelTrace is the log level of conf diagnostic events: loading, validating,
+// setting and saving config values. It is lower than slog.LevelDebug, so the
+// logger handler level should be set to LevelTrace to get the events.
+const LevelTrace = slog.LevelDebug - 4
+
+// Redacted replaces values of secret fields in log events.
+const Redacted = "******"
+
+// Secret is implemented by types which values are sensitive and must not be
+// written to logs, e.g. passwords.
+type Secret interface {
+	IsSecret() bool
+}
+
+// logger is the conf diagnostics logger.
+var logger atomic.Pointer[slog.Logger]
+
+func init() { SetLogger(nil) }
+
+// SetLogger sets the logger of conf diagnostics. The nil logger discards all
+// events, it is the default. Values of fields implementing Secret and values
+// containing them are redacted in log events.
+func SetLogger(l *slog.Logger) {
+	if l == nil {
+		l = slog.New(discardHandler{})
+	}
+	logger.Store(l)
+}
+
+// Logger returns the logger of conf diagnostics.
+func Logger() *slog.Logger { return logger.Load() }
+
+// tracing checks if the logger trace level is enabled. It is used to skip
+// making arguments of trace events in hot paths.
+func tracing() bool {
+	return logger.Load().Enabled(context.Background(), LevelTrace)
+}
+
+// trace writes the trace event to the logger if trace level is enabled.
+func trace(msg string, args ...any) {
+	l, ctx := logger.Load(), context.Background()
+	if l.Enabled(ctx, LevelTrace) {
+		l.Log(ctx, LevelTrace, msg, args...)
+	}
+}
+
+// redact returns Redacted instead of value if v is secret or contains secret
+// values in nested fields, map values or slice elements.
+func redact(v, value any) any {
+	if hasSecret(reflect.ValueOf(v), make(map[uintptr]bool)) {
+		return Redacted
+	}
+	return value
+}
+
+// hasSecret checks if the value or its nested values are secret. The visited
+// pointers are skipped.
+func hasSecret(v reflect.Value, visited map[uintptr]bool) bool {
+	if !v.IsValid() {
+		return false
+	}
+	if v.CanInterface() {
+		if s, ok := v.Interface().(Secret); ok && s.IsSecret() {
+			return true
+		}
+	}
+
+	switch v.Kind() {
+	case reflect.Ptr:
+		if v.IsNil() || visited[v.Pointer()] {
+			return false
+		}
+		visited[v.Pointer()] = true
+		return hasSecret(v.Elem(), visited)
+	case reflect.Interface:
+		return hasSecret(v.Elem(), visited)
+	case reflect.Struct:
+		for _, f := range planOf(v.Type()).fields {
+			if hasSecret(v.Field(f.index), visited) {
+				return true
+			}
+		}
+	case reflect.Map:
+		iter := v.MapRange()
+		for iter.Next() {
+			if hasSecret(iter.Value(), visited) {
+				return true
+			}
+		}
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			if hasSecret(v.Index(i), visited) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// discardHandler is the slog handler which discards all events.
+type discardHandler struct{}
+
+func (discardHandler) Enabled(context.Context, slog.Level) bool  { return false }
+func (discardHandler) Handle(context.Context, slog.Record) error { return nil }
+func (h discardHandler) WithAttrs([]slog.Attr) slog.Handler      { return h }
+func (h discardHandler) WithGroup(string) slog.Handler           { return h }
@@ -0,0 +1,65 @@
+package conf
+
+import (
+	"bytes"
+	"log/slog"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+type logPassword string
+
+func (logPassword) IsSecret() bool { return true }
+
+func TestLogger(t *testing.T) {
+
+	var buf bytes.Buffer
+	SetLogger(slog.New(slog.NewTextHandler(&buf,
+		&slog.HandlerOptions{Level: LevelTrace})))
+	defer SetLogger(nil)
+
+	type db struct {
+		Password logPassword
+	}
+	type config struct {
+		User     string
+		Password logPassword
+		DB       db
+	}
+	c := config{"admin", "qwerty", db{"dbpass"}}
+
+	fields := GetFields(c, func(*Field[any]) {})
+	if err := fields[1].SetValue(&c, "secret"); err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(t.TempDir(), "config.json")
+	if err := Save(path, c); err != nil {
+		t.Fatal(err)
+	}
+	if err := Load(path, &c); err != nil {
+		t.Fatal(err)
+	}
+
+	log := buf.String()
+	for _, s := range []string{`msg="get field" name=User`, "value=admin",
+		`msg="set field" name=Password`, `msg="get field" name=DB`,
+		`msg="save config"`,
+		`msg="load config"`} {
+		if !strings.Contains(log, s) {
+			t.Fatalf("log should contain %s:\n%s", s, log)
+		}
+	}
+	if strings.Contains(log, "qwerty") || strings.Contains(log, "secret") ||
+		strings.Contains(log, "dbpass") {
+		t.Fatalf("log should not contain passwords:\n%s", log)
+	}
+
+	// Silent by default
+	buf.Reset()
+	SetLogger(nil)
+	GetFields(c, func(*Field[any]) {})
+	if buf.Len() != 0 {
+		t.Fatal("default logger should be silent")
+	}
+}
@@ -0,0 +1,99 @@
+// Copyright 2024 Kirill Scherba <kirill@scherba.ru>. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Config helper go package. Merge module merges three versions of the
+// configuration field by field.
+
+package conf
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// MergeConflict is a field changed both in ours and theirs configurations to
+// different values.
+type MergeConflict struct {
+	Path   string // Field path, JSON Pointer
+	Base   any    // Base field value, nil if BaseExists is false
+	Ours   any    // Our field value, nil if OursExists is false
+	Theirs any    // Their field value, nil if TheirsExists is false
+
+	BaseExists   bool // Field exists in base
+	OursExists   bool // Field exists in ours
+	TheirsExists bool // Field exists in theirs
+}
+
+// String returns human-readable conflict description.
+func (c MergeConflict) String() string {
+	return fmt.Sprintf("%s: base %v, ours %v, theirs %v", c.Path, c.Base,
+		c.Ours, c.Theirs)
+}
+
+// Resolve sets the conflicting field in the configuration p points to to our
+// or their value. The p is usually the merged value returned by Merge3. The
+// field is removed if the chosen side has no such field, see OursExists and
+// TheirsExists, so an existing nil value is set rather than removed.
+func (c MergeConflict) Resolve(p any, theirs bool) error {
+	value, exists := c.Ours, c.OursExists
+	if theirs {
+		value, exists = c.Theirs, c.TheirsExists
+	}
+	op := Operation{Op: "add", Path: c.Path, Value: value}
+	if !exists {
+		op = Operation{Op: "remove", Path: c.Path}
+	}
+	return ApplyPatch(p, Patch{op})
+}
+
+// Merge3 merges changes made in ours and theirs configurations since the
+// common base configuration. It is used to upgrade customized config with new
+// default config: base is the previous default config, ours is the user
+// config and theirs is the new default config.
+//
+// Fields are compared by path the same way as in Diff. Fields changed only in
+// theirs get their values, fields changed only in ours or changed in both to
+// the same value keep our values. Fields changed in both to different values
+// keep our values and are returned as conflicts which may be resolved with
+// MergeConflict.Resolve.
+func Merge3[T any](base, ours, theirs T) (merged T, conflicts []MergeConflict,
+	err error) {
+
+	merged = Clone(ours)
+	baseVal, oursVal := reflect.ValueOf(&base), reflect.ValueOf(&ours)
+
+	var patch Patch
+	for _, d := range Diff(base, theirs) {
+		segs, _ := parsePointer(d.Path)
+		b, bok := mergeFieldValue(baseVal, segs)
+		o, ook := mergeFieldValue(oursVal, segs)
+		switch {
+		// Not changed in ours, take theirs
+		case bok == ook && reflect.DeepEqual(b, o):
+			patch = append(patch, d.operation())
+
+		// Changed in ours to the same value
+		case ook == (d.Kind != DiffRemoved) &&
+			reflect.DeepEqual(o, d.NewValue):
+
+		default:
+			conflicts = append(conflicts, MergeConflict{Path: d.Path,
+				Base: d.OldValue, Ours: o, Theirs: d.NewValue,
+				BaseExists: d.Kind != DiffAdded, OursExists: ook,
+				TheirsExists: d.Kind != DiffRemoved})
+		}
+	}
+	err = ApplyPatch(&merged, patch)
+	return
+}
+
+// mergeFieldValue returns the field value by path segments and true if the
+// field exists.
+func mergeFieldValue(v reflect.Value, segs []string) (any, bool) {
+	field, err := getPath(v, segs)
+	if err != nil || !field.IsValid() || !field.CanInterface() {
+		return nil, false
+	}
+	return field.Interface(), true
+}
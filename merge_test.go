@@ -0,0 +1,96 @@
+package conf
+
+import (
+	"reflect"
+	"testing"
+)
+
+type mergeConfig struct {
+	Name    string
+	Port    int
+	Timeout int
+	Labels  map[string]string
+}
+
+func TestMerge3(t *testing.T) {
+
+	base := mergeConfig{Name: "app", Port: 80, Timeout: 10,
+		Labels: map[string]string{"env": "dev", "old": "x"}}
+
+	// User changed the name and the port
+	ours := Clone(base)
+	ours.Name = "my app"
+	ours.Port = 8080
+
+	// New defaults change the port and the timeout, add and remove labels
+	theirs := Clone(base)
+	theirs.Port = 81
+	theirs.Timeout = 20
+	theirs.Labels["new"] = "y"
+	delete(theirs.Labels, "old")
+
+	merged, conflicts, err := Merge3(base, ours, theirs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := mergeConfig{Name: "my app", Port: 8080, Timeout: 20,
+		Labels: map[string]string{"env": "dev", "new": "y"}}
+	if !reflect.DeepEqual(merged, want) {
+		t.Fatalf("got %+v, want %+v", merged, want)
+	}
+	if len(conflicts) != 1 || conflicts[0].Path != "/Port" ||
+		conflicts[0].Ours != 8080 || conflicts[0].Theirs != 81 {
+		t.Fatalf("wrong conflicts: %v", conflicts)
+	}
+	if !reflect.DeepEqual(ours.Labels, base.Labels) {
+		t.Fatal("ours should not be changed")
+	}
+
+	// Resolve conflict with their value
+	if err = conflicts[0].Resolve(&merged, true); err != nil {
+		t.Fatal(err)
+	}
+	if merged.Port != 81 {
+		t.Fatalf("wrong resolved port: %d", merged.Port)
+	}
+
+	// Maps
+	m, conflicts, err := Merge3(
+		map[string]any{"a": 1.0, "b": 2.0},
+		map[string]any{"a": 1.0, "b": 3.0},
+		map[string]any{"a": 5.0, "b": 2.0, "c": true},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(conflicts) != 0 ||
+		!reflect.DeepEqual(m, map[string]any{"a": 5.0, "b": 3.0, "c": true}) {
+		t.Fatalf("wrong map merge: %v, %v", m, conflicts)
+	}
+
+	// Existing nil values are chosen, not removed
+	m, conflicts, err = Merge3(
+		map[string]any{"a": 1.0},
+		map[string]any{"a": nil},
+		map[string]any{},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(conflicts) != 1 || !conflicts[0].OursExists ||
+		conflicts[0].TheirsExists || !conflicts[0].BaseExists {
+		t.Fatalf("wrong conflicts: %+v", conflicts)
+	}
+	if err = conflicts[0].Resolve(&m, false); err != nil {
+		t.Fatal(err)
+	}
+	if v, ok := m["a"]; !ok || v != nil {
+		t.Fatalf("nil value should be kept, got %v", m)
+	}
+	if err = conflicts[0].Resolve(&m, true); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := m["a"]; ok {
+		t.Fatalf("removed value should be removed, got %v", m)
+	}
+}
@@ -25,8 +25,8 @@ type Number interface {
 //
 // The function takes a string `s` as input and attempts to convert it into a
 // number of type T. The function supports number types such as int, int8,
-// int16, int32, int64, uint, uint8, uint16, uint32, uint64, float32, and
-// float64.
+// int16, int32, int64, uint, uint8, uint16, uint32, uint64, uintptr, float32,
+// and float64.
 //
 // Parameters:
 //   - s: the string to be parsed.
@@ -47,7 +47,8 @@ func ParseNumber[T Number](s string) (n T, err error) {
 
 	switch any(n).(type) {
 
-	case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64:
+	case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64,
+		uintptr:
 		i, err := strconv.Atoi(s)
 		return T(i), err
 
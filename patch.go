@@ -0,0 +1,319 @@
+// Copyright 2024 Kirill Scherba <kirill@scherba.ru>. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Config helper go package. Patch module applies JSON Patch (RFC 6902) and
+// JSON Merge Patch (RFC 7396) documents to config values.
+
+package conf
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+)
+
+// Operation is a JSON Patch operation.
+type Operation struct {
+	Op    string // Operation: add, remove, replace, move, copy or test
+	Path  string // Target JSON Pointer
+	From  string // Source JSON Pointer of move and copy operations
+	Value any    // Value of add, replace and test operations
+}
+
+// Patch is a JSON Patch document.
+type Patch []Operation
+
+// ParsePatch parses JSON Patch document.
+func ParsePatch(data []byte) (patch Patch, err error) {
+	err = json.Unmarshal(data, &patch)
+	return
+}
+
+// MarshalJSON encodes the operation to JSON. The value is encoded only for
+// operations which have a value.
+func (op Operation) MarshalJSON() ([]byte, error) {
+	m := map[string]any{"op": op.Op, "path": op.Path}
+	switch op.Op {
+	case "add", "replace", "test":
+		m["value"] = op.Value
+	case "move", "copy":
+		m["from"] = op.From
+	}
+	return json.Marshal(m)
+}
+
+// UnmarshalJSON decodes the operation from JSON.
+func (op *Operation) UnmarshalJSON(data []byte) (err error) {
+	var o struct {
+		Op    string          `json:"op"`
+		Path  *string         `json:"path"`
+		From  string          `json:"from"`
+		Value json.RawMessage `json:"value"`
+	}
+	if err = json.Unmarshal(data, &o); err != nil {
+		return
+	}
+	if o.Path == nil {
+		return fmt.Errorf("patch operation %s has no path", o.Op)
+	}
+	*op = Operation{Op: o.Op, Path: *o.Path, From: o.From}
+	if o.Value != nil {
+		err = json.Unmarshal(o.Value, &op.Value)
+	}
+	return
+}
+
+// Patch returns the JSON Patch which changes the old configuration to the new
+// one.
+func (d Differences) Patch() (patch Patch) {
+	for _, diff := range d {
+		patch = append(patch, diff.operation())
+	}
+	return
+}
+
+// operation returns the JSON Patch operation which makes the difference.
+func (d Difference) operation() Operation {
+	switch d.Kind {
+	case DiffRemoved:
+		return Operation{Op: "remove", Path: d.Path}
+	case DiffModified:
+		return Operation{Op: "replace", Path: d.Path, Value: d.NewValue}
+	}
+	return Operation{Op: "add", Path: d.Path, Value: d.NewValue}
+}
+
+// ApplyPatch applies the JSON Patch to p. The p is a pointer to a struct, a
+// map or a pointer to a map.
+//
+// Paths are resolved in structs, maps and slices. Struct fields are found by
+// Go name, json tag name or Go name ignoring case. Values are converted to the
+// field types with the SetValue conversion rules for strings and with the
+// encoding/json package for other values. Removed struct fields are set to
+// zero values.
+//
+// The patch is applied atomically: all operations are applied to a copy of p
+// which replaces p only when all operations succeed. Field subscribers are
+// notified about changed fields.
+func ApplyPatch(p any, patch Patch) error {
+	return applyAtomic(p, func(v reflect.Value) (reflect.Value, error) {
+		for i, op := range patch {
+			var err error
+			if v, err = applyOperation(v, op); err != nil {
+				return v, fmt.Errorf("patch operation %d (%s): %w", i, op.Op,
+					err)
+			}
+		}
+		return v, nil
+	})
+}
+
+// ApplyMergePatch applies the JSON Merge Patch document to p the same way as
+// ApplyPatch. Null members of the merge patch remove fields, object members
+// are merged recursively and other members replace the field values.
+func ApplyMergePatch(p any, data []byte) (err error) {
+	var patch any
+	if err = json.Unmarshal(data, &patch); err != nil {
+		return
+	}
+	return applyAtomic(p, func(v reflect.Value) (reflect.Value, error) {
+		return mergePatch(v, nil, patch)
+	})
+}
+
+// applyOperation applies the JSON Patch operation to v.
+func applyOperation(v reflect.Value, op Operation) (reflect.Value, error) {
+	segs, err := parsePointer(op.Path)
+	if err != nil {
+		return v, err
+	}
+
+	switch op.Op {
+	case "add":
+		return setPath(v, segs, addOp(op.Value))
+	case "replace":
+		return setPath(v, segs, replaceOp(op.Value))
+	case "remove":
+		if len(segs) == 0 {
+			return v, fmt.Errorf("can't remove the whole value")
+		}
+		return updatePath(v, segs, removeOp())
+
+	case "move", "copy":
+		from, err := parsePointer(op.From)
+		if err != nil {
+			return v, err
+		}
+		if op.Op == "move" && hasPathPrefix(segs, from) && len(segs) > len(from) {
+			return v, fmt.Errorf("can't move %s to its child", op.From)
+		}
+		val, err := getPath(v, from)
+		if err != nil {
+			return v, err
+		}
+		value := deepCopy(val).Interface()
+		if op.Op == "move" {
+			if len(from) == 0 {
+				return v, fmt.Errorf("can't move the whole value")
+			}
+			if v, err = updatePath(v, from, removeOp()); err != nil {
+				return v, err
+			}
+		}
+		return setPath(v, segs, addOp(value))
+
+	case "test":
+		val, err := getPath(v, segs)
+		if err != nil {
+			return v, err
+		}
+		if !jsonEqual(val.Interface(), op.Value) {
+			return v, fmt.Errorf("test failed: %s is not %v", op.Path, op.Value)
+		}
+		return v, nil
+	}
+	return v, fmt.Errorf("unknown patch operation %q", op.Op)
+}
+
+// mergePatch applies the JSON Merge Patch to the value by path segments.
+func mergePatch(v reflect.Value, segs []string, patch any) (reflect.Value,
+	error) {
+
+	obj, ok := patch.(map[string]any)
+	if !ok {
+		return setPath(v, segs, replaceOp(patch))
+	}
+
+	// Replace not object target value with merge patch object
+	if target, err := getPath(v, segs); err != nil ||
+		!isMergeContainer(indirect(target)) {
+		return setPath(v, segs, addOp(mergeValue(obj)))
+	}
+
+	for _, key := range mapKeys(obj) {
+		path := append(append([]string{}, segs...), key)
+		val := obj[key]
+		var err error
+		switch {
+		case val == nil:
+			if _, e := getPath(v, path); e != nil {
+				continue
+			}
+			v, err = updatePath(v, path, removeOp())
+		default:
+			if _, isObj := val.(map[string]any); !isObj {
+				v, err = setPath(v, path, addOp(val))
+				break
+			}
+			v, err = mergePatch(v, path, val)
+		}
+		if err != nil {
+			return v, err
+		}
+	}
+	return v, nil
+}
+
+// mergeValue returns the merge patch object without null members.
+func mergeValue(obj map[string]any) map[string]any {
+	m := make(map[string]any, len(obj))
+	for key, val := range obj {
+		switch val := val.(type) {
+		case nil:
+		case map[string]any:
+			m[key] = mergeValue(val)
+		default:
+			m[key] = val
+		}
+	}
+	return m
+}
+
+// isMergeContainer checks if the merge patch object is merged to the value
+// member by member.
+func isMergeContainer(v reflect.Value) bool {
+	return v.Kind() == reflect.Struct ||
+		(v.Kind() == reflect.Map && v.Type().Key().Kind() == reflect.String)
+}
+
+// setPath applies the operation by path segments. The empty path sets the
+// whole value.
+func setPath(v reflect.Value, segs []string, op pathOp) (reflect.Value, error) {
+	if len(segs) > 0 {
+		return updatePath(v, segs, op)
+	}
+
+	// Set the whole value with the operation applied to a wrapper struct
+	wrapper := reflect.New(reflect.StructOf([]reflect.StructField{
+		{Name: "V", Type: v.Type()},
+	})).Elem()
+	wrapper, err := op(wrapper, "V")
+	if err != nil {
+		return v, err
+	}
+	return wrapper.Field(0), nil
+}
+
+// applyAtomic applies the function f to a deep copy of the value p points to
+// and replaces the value with the result if f succeeds. The p is a pointer or
+// a map. Subscribers of p are notified about changed fields.
+func applyAtomic(p any, f func(v reflect.Value) (reflect.Value, error)) (
+	err error) {
+
+	if isDocument(p) {
+		return fmt.Errorf("can't change value of type %T", p)
+	}
+
+	pv := reflect.ValueOf(p)
+	switch {
+	case pv.Kind() == reflect.Ptr && !pv.IsNil():
+		v, err := f(deepCopy(pv.Elem()))
+		if err != nil {
+			return err
+		}
+		old := reflect.New(pv.Elem().Type())
+		old.Elem().Set(pv.Elem())
+		pv.Elem().Set(v)
+		if subscribed(p) {
+			notify(p, fieldChanges(old.Interface(), p)...)
+		}
+
+	case pv.Kind() == reflect.Map:
+		v, err := f(deepCopy(pv))
+		if err != nil {
+			return err
+		}
+		old := deepCopy(pv)
+		pv.Clear()
+		iter := v.MapRange()
+		for iter.Next() {
+			pv.SetMapIndex(iter.Key(), iter.Value())
+		}
+		if subscribed(p) {
+			notify(p, fieldChanges(old.Interface(), p)...)
+		}
+
+	default:
+		return fmt.Errorf("can't change value of type %T", p)
+	}
+	return
+}
+
+// jsonEqual checks if a and b have the same JSON encoding.
+func jsonEqual(a, b any) bool {
+	var va, vb any
+	for _, p := range []struct {
+		v   any
+		dst *any
+	}{{a, &va}, {b, &vb}} {
+		data, err := json.Marshal(p.v)
+		if err != nil {
+			return false
+		}
+		if err = json.Unmarshal(data, p.dst); err != nil {
+			return false
+		}
+	}
+	return reflect.DeepEqual(va, vb)
+}
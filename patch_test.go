@@ -0,0 +1,114 @@
+package conf
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+type patchServer struct {
+	Host string `json:"host"`
+	Port int    `json:"port"`
+}
+
+type patchConfig struct {
+	Name    string            `json:"name"`
+	Servers []patchServer     `json:"servers"`
+	Labels  map[string]string `json:"labels"`
+}
+
+func TestApplyPatch(t *testing.T) {
+
+	c := patchConfig{
+		Name:    "app",
+		Servers: []patchServer{{"a", 80}},
+		Labels:  map[string]string{"env": "dev"},
+	}
+
+	patch, err := ParsePatch([]byte(`[
+		{"op": "test", "path": "/name", "value": "app"},
+		{"op": "replace", "path": "/servers/0/port", "value": "8080"},
+		{"op": "add", "path": "/servers/-", "value": {"host": "b", "port": 81}},
+		{"op": "copy", "from": "/labels/env", "path": "/labels/stage"},
+		{"op": "remove", "path": "/labels/env"}
+	]`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = ApplyPatch(&c, patch); err != nil {
+		t.Fatal(err)
+	}
+	want := patchConfig{
+		Name:    "app",
+		Servers: []patchServer{{"a", 8080}, {"b", 81}},
+		Labels:  map[string]string{"stage": "dev"},
+	}
+	if !reflect.DeepEqual(c, want) {
+		t.Fatalf("got %+v, want %+v", c, want)
+	}
+
+	// Failed patch changes nothing
+	patch = Patch{
+		{Op: "replace", Path: "/name", Value: "changed"},
+		{Op: "replace", Path: "/servers/0/port", Value: "not a number"},
+	}
+	if err = ApplyPatch(&c, patch); err == nil {
+		t.Fatal("invalid port should be an error")
+	}
+	if !reflect.DeepEqual(c, want) {
+		t.Fatalf("failed patch should not change config: %+v", c)
+	}
+
+	// Patch from diff
+	changed := Clone(c)
+	changed.Name = "new"
+	changed.Servers[1].Port = 82
+	diffPatch := Diff(c, changed).Patch()
+	data, err := json.Marshal(diffPatch)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if patch, err = ParsePatch(data); err != nil {
+		t.Fatal(err)
+	}
+	if err = ApplyPatch(&c, patch); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(c, changed) {
+		t.Fatalf("got %+v, want %+v", c, changed)
+	}
+}
+
+func TestApplyMergePatch(t *testing.T) {
+
+	m := map[string]any{
+		"name": "app",
+		"db":   map[string]any{"host": "a", "port": 5432.0},
+		"old":  true,
+	}
+	err := ApplyMergePatch(m, []byte(`{
+		"db": {"port": 6432, "user": "admin"},
+		"old": null,
+		"tags": ["a", "b"]
+	}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]any{
+		"name": "app",
+		"db":   map[string]any{"host": "a", "port": 6432.0, "user": "admin"},
+		"tags": []any{"a", "b"},
+	}
+	if !reflect.DeepEqual(m, want) {
+		t.Fatalf("got %v, want %v", m, want)
+	}
+
+	c := patchConfig{Name: "app"}
+	err = ApplyMergePatch(&c, []byte(`{"name": "new", "labels": {"a": "b"}}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.Name != "new" || c.Labels["a"] != "b" {
+		t.Fatalf("wrong struct merge: %+v", c)
+	}
+}
@@ -0,0 +1,488 @@
+// Copyright 2024 Kirill Scherba <kirill@scherba.ru>. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Config helper go package. Path module resolves field paths in structs, maps
+// and slices.
+
+package conf
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// Get returns the value by path in o. The o is a struct, a map, a slice or a
+// pointer to them. The path is JSON Pointer (RFC 6901), e.g.
+// "/database/pool/max", or dotted path, e.g. "database.pool.max". Struct
+// fields are found by Go name, json tag name or Go name ignoring case, slice
+// elements are found by index.
+func Get(o any, path string) (value any, err error) {
+	segs, err := parsePath(path)
+	if err != nil {
+		return
+	}
+	v, err := getPath(reflect.ValueOf(o), segs)
+	if err != nil {
+		return
+	}
+	if !v.IsValid() || !v.CanInterface() {
+		return nil, &PathError{path, fmt.Errorf("can't get value")}
+	}
+	return v.Interface(), nil
+}
+
+// Set sets the value by path in the configuration p points to. The p is a
+// pointer to a struct or a slice, a map or a pointer to a map. The path is the
+// same as in Get, the "-" last segment appends the value to the end of the
+// slice. The string value is converted to the field type with the SetValue
+// conversion rules, structs and maps are decoded from JSON. Existing members of
+// map[string]any and []any keep the type of their current value.
+//
+// The value is set atomically: p is not changed if the value can't be set.
+// Field subscribers are notified about changed fields.
+func Set(p any, path string, value string) error {
+	segs, err := parsePath(path)
+	if err != nil {
+		return err
+	}
+	return applyAtomic(p, func(v reflect.Value) (reflect.Value, error) {
+		return setPath(v, segs, setOp(textValue(value)))
+	})
+}
+
+// parsePath parses JSON Pointer or dotted path to unescaped path segments.
+func parsePath(path string) ([]string, error) {
+	if path == "" || strings.HasPrefix(path, "/") {
+		return parsePointer(path)
+	}
+	return strings.Split(path, "."), nil
+}
+
+// parsePointer parses JSON Pointer (RFC 6901) to unescaped path segments. The
+// empty pointer is the whole value and has no segments.
+func parsePointer(pointer string) (segs []string, err error) {
+	if pointer == "" {
+		return
+	}
+	if !strings.HasPrefix(pointer, "/") {
+		return nil, fmt.Errorf("invalid JSON pointer %q", pointer)
+	}
+	unescape := strings.NewReplacer("~1", "/", "~0", "~")
+	for _, seg := range strings.Split(pointer[1:], "/") {
+		segs = append(segs, unescape.Replace(seg))
+	}
+	return
+}
+
+// pathOp is an operation on the path parent container. It gets the container
+// and the last path segment and returns the changed container.
+type pathOp func(parent reflect.Value, key string) (reflect.Value, error)
+
+// getPath returns the value by path segments.
+func getPath(v reflect.Value, segs []string) (reflect.Value, error) {
+	for i, seg := range segs {
+		v = indirect(v)
+		child, err := childValue(v, seg)
+		if err != nil {
+			return child, pathError(segs[:i+1], err)
+		}
+		v = child
+	}
+	return v, nil
+}
+
+// updatePath applies the operation to the parent container of the last path
+// segment and returns v with the changed container. Containers which are not
+// addressable (structs in maps or interfaces) are copied and changed copies
+// are set to their parents.
+func updatePath(v reflect.Value, segs []string, op pathOp) (reflect.Value,
+	error) {
+
+	switch v.Kind() {
+	case reflect.Interface:
+		if v.IsNil() {
+			return v, fmt.Errorf("nil value")
+		}
+		child, err := updatePath(v.Elem(), segs, op)
+		if err != nil {
+			return v, err
+		}
+		c := reflect.New(v.Type()).Elem()
+		c.Set(child)
+		return c, nil
+
+	case reflect.Ptr:
+		if v.IsNil() {
+			return v, fmt.Errorf("nil pointer")
+		}
+		child, err := updatePath(v.Elem(), segs, op)
+		if err != nil {
+			return v, err
+		}
+		v.Elem().Set(child)
+		return v, nil
+	}
+
+	if len(segs) == 1 {
+		c, err := op(v, segs[0])
+		if err != nil {
+			return v, pathError(segs, err)
+		}
+		return c, nil
+	}
+
+	child, err := childValue(v, segs[0])
+	if err != nil {
+		return v, pathError(segs[:1], err)
+	}
+	child, err = updatePath(child, segs[1:], op)
+	if err != nil {
+		return v, pathError(segs[:1], err)
+	}
+	return setChild(v, segs[0], child)
+}
+
+// addOp returns the operation which sets the object member or the struct
+// field, or inserts the array element. The "-" key appends the element to the
+// end of the array.
+func addOp(value any) pathOp {
+	return func(parent reflect.Value, key string) (reflect.Value, error) {
+		switch parent.Kind() {
+		case reflect.Struct, reflect.Map:
+			return setMember(parent, key, value, false)
+		case reflect.Slice:
+			i := parent.Len()
+			if key != "-" {
+				var err error
+				if i, err = sliceIndex(parent, key, true); err != nil {
+					return parent, err
+				}
+			}
+			e, err := convertValue(value, parent.Type().Elem())
+			if err != nil {
+				return parent, err
+			}
+			c := reflect.MakeSlice(parent.Type(), 0, parent.Len()+1)
+			c = reflect.AppendSlice(c, parent.Slice(0, i))
+			c = reflect.Append(c, e)
+			return reflect.AppendSlice(c, parent.Slice(i, parent.Len())), nil
+		}
+		return parent, unsupportedError(parent)
+	}
+}
+
+// replaceOp returns the operation which replaces existing value.
+func replaceOp(value any) pathOp {
+	return func(parent reflect.Value, key string) (reflect.Value, error) {
+		switch parent.Kind() {
+		case reflect.Struct, reflect.Map:
+			return setMember(parent, key, value, true)
+		case reflect.Slice, reflect.Array:
+			i, err := sliceIndex(parent, key, false)
+			if err != nil {
+				return parent, err
+			}
+			t := valueType(value, parent.Type().Elem(), parent.Index(i))
+			e, err := convertValue(value, t)
+			if err != nil {
+				return parent, err
+			}
+			return setChild(parent, strconv.Itoa(i), e)
+		}
+		return parent, unsupportedError(parent)
+	}
+}
+
+// setOp returns the operation which sets the object member or the struct field,
+// or replaces the array element. The "-" key appends the element to the end of
+// the array.
+func setOp(value any) pathOp {
+	return func(parent reflect.Value, key string) (reflect.Value, error) {
+		if parent.Kind() == reflect.Slice && key == "-" {
+			return addOp(value)(parent, key)
+		}
+		if parent.Kind() == reflect.Slice || parent.Kind() == reflect.Array {
+			return replaceOp(value)(parent, key)
+		}
+		return addOp(value)(parent, key)
+	}
+}
+
+// removeOp returns the operation which removes existing value. Struct fields
+// and array elements can't be removed and are set to zero value.
+func removeOp() pathOp {
+	return func(parent reflect.Value, key string) (reflect.Value, error) {
+		switch parent.Kind() {
+		case reflect.Struct:
+			i, ok := structFieldIndex(parent.Type(), key)
+			if !ok {
+				return parent, fmt.Errorf("field not found")
+			}
+			c := reflect.New(parent.Type()).Elem()
+			c.Set(parent)
+			c.Field(i).Set(reflect.Zero(c.Field(i).Type()))
+			return c, nil
+		case reflect.Map:
+			k, err := mapKey(parent, key)
+			if err != nil {
+				return parent, err
+			}
+			if !parent.MapIndex(k).IsValid() {
+				return parent, fmt.Errorf("member not found")
+			}
+			parent.SetMapIndex(k, reflect.Value{})
+			return parent, nil
+		case reflect.Slice:
+			i, err := sliceIndex(parent, key, false)
+			if err != nil {
+				return parent, err
+			}
+			c := reflect.MakeSlice(parent.Type(), 0, parent.Len()-1)
+			c = reflect.AppendSlice(c, parent.Slice(0, i))
+			return reflect.AppendSlice(c, parent.Slice(i+1, parent.Len())), nil
+		case reflect.Array:
+			if _, err := sliceIndex(parent, key, false); err != nil {
+				return parent, err
+			}
+			return setChild(parent, key, reflect.Zero(parent.Type().Elem()))
+		}
+		return parent, unsupportedError(parent)
+	}
+}
+
+// setMember sets the struct field or the map member value. If exists is true
+// the member should exist.
+func setMember(parent reflect.Value, key string, value any, exists bool) (
+	reflect.Value, error) {
+
+	switch parent.Kind() {
+	case reflect.Struct:
+		i, ok := structFieldIndex(parent.Type(), key)
+		if !ok {
+			return parent, fmt.Errorf("field not found")
+		}
+		e, err := convertValue(value, parent.Type().Field(i).Type)
+		if err != nil {
+			return parent, err
+		}
+		c := reflect.New(parent.Type()).Elem()
+		c.Set(parent)
+		c.Field(i).Set(e)
+		return c, nil
+
+	case reflect.Map:
+		k, err := mapKey(parent, key)
+		if err != nil {
+			return parent, err
+		}
+		if exists && !parent.MapIndex(k).IsValid() {
+			return parent, fmt.Errorf("member not found")
+		}
+		t := valueType(value, parent.Type().Elem(), parent.MapIndex(k))
+		e, err := convertValue(value, t)
+		if err != nil {
+			return parent, err
+		}
+		if parent.IsNil() {
+			m := reflect.MakeMap(parent.Type())
+			m.SetMapIndex(k, e)
+			return m, nil
+		}
+		parent.SetMapIndex(k, e)
+		return parent, nil
+	}
+	return parent, unsupportedError(parent)
+}
+
+// childValue returns the struct field, the map member or the array element.
+func childValue(v reflect.Value, key string) (reflect.Value, error) {
+	switch v.Kind() {
+	case reflect.Struct:
+		i, ok := structFieldIndex(v.Type(), key)
+		if !ok {
+			return reflect.Value{}, fmt.Errorf("field not found")
+		}
+		return v.Field(i), nil
+	case reflect.Map:
+		k, err := mapKey(v, key)
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		child := v.MapIndex(k)
+		if !child.IsValid() {
+			return child, fmt.Errorf("member not found")
+		}
+		return child, nil
+	case reflect.Slice, reflect.Array:
+		i, err := sliceIndex(v, key, false)
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		return v.Index(i), nil
+	}
+	return reflect.Value{}, unsupportedError(v)
+}
+
+// setChild sets the struct field, the map member or the array element and
+// returns the changed container.
+func setChild(v reflect.Value, key string, child reflect.Value) (reflect.Value,
+	error) {
+
+	switch v.Kind() {
+	case reflect.Struct, reflect.Array:
+		c := reflect.New(v.Type()).Elem()
+		c.Set(v)
+		if v.Kind() == reflect.Struct {
+			i, _ := structFieldIndex(v.Type(), key)
+			c.Field(i).Set(child)
+		} else {
+			i, _ := strconv.Atoi(key)
+			c.Index(i).Set(child)
+		}
+		return c, nil
+	case reflect.Map:
+		k, err := mapKey(v, key)
+		if err != nil {
+			return v, err
+		}
+		v.SetMapIndex(k, child)
+		return v, nil
+	case reflect.Slice:
+		i, _ := strconv.Atoi(key)
+		v.Index(i).Set(child)
+		return v, nil
+	}
+	return v, unsupportedError(v)
+}
+
+// structFieldIndex returns the index of the exported struct field by name.
+// The field is searched by the Go name, by the json tag name and by the Go
+// name ignoring case.
+func structFieldIndex(t reflect.Type, name string) (int, bool) {
+	f, ok := planOf(t).lookup(name)
+	if !ok {
+		return -1, false
+	}
+	return f.index, true
+}
+
+// mapKey returns the map key value. Only maps with string keys are supported.
+func mapKey(m reflect.Value, key string) (reflect.Value, error) {
+	if m.Type().Key().Kind() != reflect.String {
+		return reflect.Value{}, unsupportedError(m)
+	}
+	return reflect.ValueOf(key).Convert(m.Type().Key()), nil
+}
+
+// sliceIndex parses the array index. If insert is true the index may be equal
+// to the array length.
+func sliceIndex(v reflect.Value, key string, insert bool) (int, error) {
+	i, err := strconv.Atoi(key)
+	if err != nil || i < 0 || (len(key) > 1 && key[0] == '0') {
+		return 0, fmt.Errorf("invalid array index %q", key)
+	}
+	if i > v.Len() || (i == v.Len() && !insert) {
+		return 0, fmt.Errorf("array index %d out of range", i)
+	}
+	return i, nil
+}
+
+// textValue is a string value set by Set. It is always converted to the field
+// type, even to interface fields.
+type textValue string
+
+// convertValue converts the value to type t. Strings are converted with the
+// SetValue conversion rules, text values are converted with the SetValue
+// conversion rules or decoded from JSON, other values are converted with the
+// encoding/json package.
+func convertValue(value any, t reflect.Type) (reflect.Value, error) {
+	if value == nil {
+		return reflect.Zero(t), nil
+	}
+	if s, ok := value.(textValue); ok {
+		if c, err := parseValue(t, string(s)); err == nil {
+			return c, nil
+		}
+		c := reflect.New(t)
+		if err := json.Unmarshal([]byte(s), c.Interface()); err != nil {
+			return reflect.Value{}, fmt.Errorf("can't convert %s to %s", s, t)
+		}
+		return c.Elem(), nil
+	}
+	v := reflect.ValueOf(value)
+	if v.Type().AssignableTo(t) {
+		return v, nil
+	}
+	if s, ok := value.(string); ok {
+		if c, err := parseValue(t, s); err == nil {
+			return c, nil
+		}
+	}
+
+	data, err := json.Marshal(value)
+	if err != nil {
+		return reflect.Value{}, err
+	}
+	c := reflect.New(t)
+	if err = json.Unmarshal(data, c.Interface()); err != nil {
+		return reflect.Value{}, fmt.Errorf("can't convert %v to %s", value, t)
+	}
+	return c.Elem(), nil
+}
+
+// valueType returns the type the value set to the container element of type
+// t is converted to. Text values set to interface elements, e.g. members of
+// map[string]any, are converted to the dynamic type of the existing element,
+// other values are converted to t.
+func valueType(value any, t reflect.Type, old reflect.Value) reflect.Type {
+	if _, ok := value.(textValue); !ok || t.Kind() != reflect.Interface ||
+		!old.IsValid() || old.IsNil() {
+		return t
+	}
+	return old.Elem().Type()
+}
+
+// indirect returns the value pointed to by pointers and interfaces.
+func indirect(v reflect.Value) reflect.Value {
+	for (v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface) &&
+		!v.IsNil() {
+		v = v.Elem()
+	}
+	return v
+}
+
+// pathError returns the error with the JSON Pointer path.
+func pathError(segs []string, err error) error {
+	var pointer string
+	for _, seg := range segs {
+		pointer += "/" + escapePathSegment(seg)
+	}
+	if e, ok := err.(*PathError); ok {
+		return &PathError{pointer + e.Path, e.Err}
+	}
+	return &PathError{pointer, err}
+}
+
+// unsupportedError returns error for the value which can't contain fields.
+func unsupportedError(v reflect.Value) error {
+	if !v.IsValid() {
+		return fmt.Errorf("unsupported nil value")
+	}
+	return fmt.Errorf("unsupported type %s", v.Type())
+}
+
+// PathError is an error of the path operation.
+type PathError struct {
+	Path string // JSON Pointer path
+	Err  error  // Error
+}
+
+// Error returns the error message.
+func (e *PathError) Error() string { return e.Path + ": " + e.Err.Error() }
+
+// Unwrap returns the underlying error.
+func (e *PathError) Unwrap() error { return e.Err }
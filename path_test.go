@@ -0,0 +1,107 @@
+package conf
+
+import (
+	"reflect"
+	"testing"
+)
+
+type pathPool struct {
+	Max int `json:"max"`
+}
+
+type pathConfig struct {
+	Database struct {
+		Pool pathPool `json:"pool"`
+	} `json:"database"`
+	Servers []patchServer     `json:"servers"`
+	Tags    map[string]any    `json:"tags"`
+	Ports   [2]uint16         `json:"ports"`
+	Labels  map[string]string `json:"labels"`
+}
+
+func TestGetSet(t *testing.T) {
+
+	var c pathConfig
+	c.Database.Pool.Max = 10
+	c.Servers = []patchServer{{"a", 80}, {"b", 81}}
+	c.Tags = map[string]any{"a/b": "c"}
+
+	// Get by JSON Pointer and dotted path
+	for path, want := range map[string]any{
+		"/database/pool/max": 10,
+		"Database.Pool.Max":  10,
+		"/servers/1/port":    81,
+		"servers.0.host":     "a",
+		"/tags/a~1b":         "c",
+	} {
+		v, err := Get(c, path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if v != want {
+			t.Fatalf("%s: got %v, want %v", path, v, want)
+		}
+	}
+	if _, err := Get(&c, "/servers/2/port"); err == nil {
+		t.Fatal("out of range index should be an error")
+	}
+
+	// Set
+	for path, value := range map[string]string{
+		"/servers/1/port":   "8080",
+		"/servers/-":        `{"host": "c", "port": 82}`,
+		"database.pool.max": "20",
+		"/tags/n":           "1.5",
+		"/ports/1":          "443",
+		"/labels/env":       "prod",
+	} {
+		if err := Set(&c, path, value); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if c.Database.Pool.Max != 20 || c.Tags["n"] != 1.5 || c.Ports[1] != 443 ||
+		c.Labels["env"] != "prod" {
+		t.Fatalf("wrong values: %+v", c)
+	}
+	want := []patchServer{{"a", 80}, {"b", 8080}, {"c", 82}}
+	if !reflect.DeepEqual(c.Servers, want) {
+		t.Fatalf("got servers %v, want %v", c.Servers, want)
+	}
+
+	// Invalid value doesn't change anything
+	if err := Set(&c, "/servers/0/port", "http"); err == nil {
+		t.Fatal("invalid port should be an error")
+	}
+	if c.Servers[0].Port != 80 {
+		t.Fatal("failed Set should not change config")
+	}
+}
+
+func TestSetMapTypes(t *testing.T) {
+
+	m := map[string]any{
+		"version": "1.0",
+		"servers": []any{map[string]any{"port": 80.0}},
+	}
+
+	// New values keep the type of existing members
+	if err := Set(&m, "/version", "2"); err != nil {
+		t.Fatal(err)
+	}
+	if m["version"] != "2" {
+		t.Fatalf("version should stay string, got %T %v", m["version"],
+			m["version"])
+	}
+	if err := Set(&m, "/servers/0/port", "8080"); err != nil {
+		t.Fatal(err)
+	}
+	if port, _ := Get(m, "/servers/0/port"); port != 8080.0 {
+		t.Fatalf("port should stay float64, got %T %v", port, port)
+	}
+	if err := Set(&m, "/servers/0/port", "x"); err == nil {
+		t.Fatal("invalid port should be an error")
+	}
+	if port, _ := Get(m, "/servers/0/port"); port != 8080.0 {
+		t.Fatal("failed Set should not change config")
+	}
+}
@@ -0,0 +1,108 @@
+// Copyright 2024 Kirill Scherba <kirill@scherba.ru>. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Config helper go package. Plan module caches reflection metadata of struct
+// types.
+
+package conf
+
+import (
+	"reflect"
+	"strings"
+	"sync"
+)
+
+// typePlan is the cached description of the struct type fields. It is made
+// once per type and shared by all calls and goroutines.
+type typePlan struct {
+	fields []fieldPlan    // Exported fields in declaration order
+	byName map[string]int // Fields plan index by Go name
+	byJSON map[string]int // Fields plan index by json tag name
+	byFold map[string]int // Fields plan index by lower case Go name
+}
+
+// fieldPlan is the cached description of the struct field.
+type fieldPlan struct {
+	index   int               // Struct field index
+	name    string            // Go name
+	json    string            // Json tag name
+	typ     reflect.Type      // Field type
+	typeStr string            // Field type as string
+	tag     reflect.StructTag // Field tag
+	convert converter         // String value converter
+}
+
+// plans caches struct type plans.
+var plans sync.Map // map[reflect.Type]*typePlan
+
+// planOf returns the cached plan of the struct type t.
+func planOf(t reflect.Type) *typePlan {
+	if p, ok := plans.Load(t); ok {
+		return p.(*typePlan)
+	}
+	p, _ := plans.LoadOrStore(t, newPlan(t))
+	return p.(*typePlan)
+}
+
+// newPlan makes the plan of the struct type t.
+func newPlan(t reflect.Type) *typePlan {
+	p := &typePlan{
+		byName: make(map[string]int),
+		byJSON: make(map[string]int),
+		byFold: make(map[string]int),
+	}
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if !f.IsExported() {
+			continue
+		}
+		tag, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+		if tag == "-" {
+			tag = ""
+		}
+		p.fields = append(p.fields, fieldPlan{
+			index:   i,
+			name:    f.Name,
+			json:    tag,
+			typ:     f.Type,
+			typeStr: f.Type.String(),
+			tag:     f.Tag,
+			convert: converterOf(f.Type),
+		})
+		n := len(p.fields) - 1
+		p.byName[f.Name] = n
+		if _, ok := p.byJSON[tag]; tag != "" && !ok {
+			p.byJSON[tag] = n
+		}
+		if _, ok := p.byFold[strings.ToLower(f.Name)]; !ok {
+			p.byFold[strings.ToLower(f.Name)] = n
+		}
+	}
+	return p
+}
+
+// field returns the field plan by Go name.
+func (p *typePlan) field(name string) (*fieldPlan, bool) {
+	n, ok := p.byName[name]
+	if !ok {
+		return nil, false
+	}
+	return &p.fields[n], true
+}
+
+// lookup returns the field plan by Go name, by json tag name or by Go name
+// ignoring case.
+func (p *typePlan) lookup(name string) (*fieldPlan, bool) {
+	n, ok := p.byName[name]
+	if !ok {
+		n, ok = p.byJSON[name]
+	}
+	if !ok {
+		n, ok = p.byFold[strings.ToLower(name)]
+	}
+	if !ok {
+		return nil, false
+	}
+	return &p.fields[n], true
+}
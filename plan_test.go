@@ -0,0 +1,126 @@
+package conf
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"sync"
+	"testing"
+)
+
+// largeConfig returns a pointer to a new struct with n int, string and float
+// fields.
+func largeConfig(n int) any {
+	fields := make([]reflect.StructField, n)
+	types := []reflect.Type{reflect.TypeOf(0), reflect.TypeOf(""),
+		reflect.TypeOf(0.0)}
+	for i := range fields {
+		fields[i] = reflect.StructField{
+			Name: fmt.Sprintf("Field%d", i),
+			Type: types[i%len(types)],
+			Tag:  reflect.StructTag(fmt.Sprintf(`json:"field_%d"`, i)),
+		}
+	}
+	return reflect.New(reflect.StructOf(fields)).Interface()
+}
+
+// resetPlans removes cached type plans and converters.
+func resetPlans() {
+	for _, m := range []*sync.Map{&plans, &converters} {
+		m.Range(func(key, _ any) bool {
+			m.Delete(key)
+			return true
+		})
+	}
+}
+
+func TestPlan(t *testing.T) {
+
+	type config struct {
+		Name   string `json:"name,omitempty"`
+		hidden int
+		Port   int `json:"-"`
+	}
+	p := planOf(reflect.TypeOf(config{}))
+	if len(p.fields) != 2 || p.fields[1].index != 2 {
+		t.Fatalf("wrong plan fields: %+v", p.fields)
+	}
+	for name, want := range map[string]int{"Name": 0, "name": 0, "port": 2} {
+		if i, ok := structFieldIndex(reflect.TypeOf(config{}), name); !ok ||
+			i != want {
+			t.Fatalf("%s: got index %d, want %d", name, i, want)
+		}
+	}
+
+	// Plans are shared between goroutines
+	c := largeConfig(100)
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			fields := GetFields(c, func(*Field[any]) {})
+			if len(fields) != 100 || fields[1].Tag.Get("json") != "field_1" {
+				t.Error("wrong fields")
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+func BenchmarkGetFields(b *testing.B) {
+	c := largeConfig(2000)
+	b.Run("cached", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			GetFields(c, func(*Field[any]) {})
+		}
+	})
+	b.Run("uncached", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			resetPlans()
+			GetFields(c, func(*Field[any]) {})
+		}
+	})
+}
+
+func BenchmarkSetValue(b *testing.B) {
+	c := largeConfig(2000)
+	field := GetFields(c, func(*Field[any]) {})[1999]
+	value := strconv.Itoa(42)
+	b.Run("cached", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			if err := field.SetValue(c, value); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+	b.Run("uncached", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			resetPlans()
+			if err := field.SetValue(c, value); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
+
+func BenchmarkSetValues(b *testing.B) {
+	c := largeConfig(2000)
+	fields := GetFields(c, func(*Field[any]) {})
+	set := func(field *Field[any]) (string, bool) { return field.ValueStr, true }
+	b.Run("cached", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			if err := fields.SetValues(c, set); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+	b.Run("uncached", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			resetPlans()
+			if err := fields.SetValues(c, set); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
@@ -0,0 +1,133 @@
+// Copyright 2024 Kirill Scherba <kirill@scherba.ru>. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Config helper go package. Store module keeps config values shared between
+// goroutines.
+
+package conf
+
+import (
+	"context"
+	"reflect"
+	"sync"
+	"sync/atomic"
+)
+
+// Store is a thread-safe config store. It keeps an immutable snapshot of the
+// config value of type T behind an atomic pointer. Readers get the current
+// snapshot without locking, writers change a copy of the snapshot and publish
+// it, so readers never see half-applied changes.
+type Store[T any] struct {
+	ptr atomic.Pointer[T]
+
+	mu   sync.Mutex                // Serializes updates and subscriptions
+	subs map[int]func(old, new *T) // Subscribers
+	next int                       // Next subscriber id
+}
+
+// NewStore creates and returns new store with the initial value v.
+func NewStore[T any](v T) *Store[T] {
+	s := &Store[T]{subs: make(map[int]func(old, new *T))}
+	s.ptr.Store(&v)
+	return s
+}
+
+// valueType returns the config type T, field subscriptions of the store are
+// resolved in it.
+func (s *Store[T]) valueType() reflect.Type {
+	return reflect.TypeOf((*T)(nil)).Elem()
+}
+
+// Get returns the current config snapshot. The snapshot is shared between all
+// readers and must not be changed, use Update to change the config.
+func (s *Store[T]) Get() *T { return s.ptr.Load() }
+
+// Update changes the config in the transaction. The function f gets a deep
+// copy of the current snapshot and changes it. If f returns nil and the changed
+// value is valid (if *T implements the Validator interface), the changed
+// value is published as the new snapshot and subscribers are notified. Field
+// subscribers added with the Subscribe function for this store are notified
+// about changed fields. Otherwise the current snapshot stays unchanged and the
+// error is returned.
+//
+// Updates are serialized, f should not call Update of the same store.
+func (s *Store[T]) Update(f func(v *T) error) (err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	old := s.ptr.Load()
+	v := Clone(*old)
+	if err = f(&v); err != nil {
+		return
+	}
+	if err = Validate(&v); err != nil {
+		return
+	}
+	s.ptr.Store(&v)
+
+	for _, sub := range s.subs {
+		sub(old, &v)
+	}
+	if subscribed(s) {
+		notify(s, fieldChanges(old, &v)...)
+	}
+	return
+}
+
+// Subscribe adds the function which is called with the old and the new
+// snapshots after each published update. Subscribers are called from the
+// goroutine which calls Update and should not call Update of the same store.
+// Subscribe returns the function which removes the subscription.
+func (s *Store[T]) Subscribe(f func(old, new *T)) (unsubscribe func()) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	id := s.next
+	s.next++
+	s.subs[id] = f
+
+	return func() {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		delete(s.subs, id)
+	}
+}
+
+// Load loads the config file to the store. The loaded value is validated and
+// published the same way as in Update.
+func (s *Store[T]) Load(path string) error {
+	var v T
+	if err := Load(path, &v); err != nil {
+		return err
+	}
+	return s.Update(func(cur *T) error { *cur = v; return nil })
+}
+
+// Watch watches the config file and publishes it in the store when the file
+// changes. See the Watch function for details.
+func (s *Store[T]) Watch(ctx context.Context, path string,
+	onChange func(fields []string, err error)) (err error) {
+
+	watcher, err := newWatcher(path)
+	if err != nil {
+		return
+	}
+
+	// Publish changed value
+	store := func(v any) (fields []string, err error) {
+		changes := fieldChanges(s.Get(), v)
+		if len(changes) == 0 {
+			return
+		}
+		err = s.Update(func(cur *T) error { *cur = *v.(*T); return nil })
+		if err != nil {
+			return
+		}
+		return changePaths(changes), nil
+	}
+
+	t := reflect.TypeOf((*T)(nil)).Elem()
+	go watch(ctx, watcher, NewFile(path), t, store, onChange)
+	return
+}
@@ -0,0 +1,63 @@
+package conf
+
+import (
+	"errors"
+	"sync"
+	"testing"
+)
+
+func TestStore(t *testing.T) {
+
+	type config struct {
+		Name  string
+		Ports []int
+	}
+
+	store := NewStore(config{Name: "a", Ports: []int{80}})
+
+	var notified int
+	unsubscribe := store.Subscribe(func(old, new *config) {
+		notified++
+		if old.Name == new.Name {
+			t.Errorf("name should be changed: %s", new.Name)
+		}
+	})
+
+	// Concurrent readers and writers
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(2)
+		go func(i int) {
+			defer wg.Done()
+			store.Update(func(c *config) error {
+				c.Name = string(rune('b' + i))
+				c.Ports[0] = 80 + i
+				c.Ports = append(c.Ports, i)
+				return nil
+			})
+		}(i)
+		go func() {
+			defer wg.Done()
+			c := store.Get()
+			if c.Name != "a" && c.Ports[0]-80 != int(c.Name[0]-'b') {
+				t.Errorf("half-applied config: %+v", c)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if c := store.Get(); len(c.Ports) != 11 || notified != 10 {
+		t.Fatalf("wrong store state: %+v, notified %d", c, notified)
+	}
+
+	// Failed update is not published
+	unsubscribe()
+	before := store.Get()
+	err := store.Update(func(c *config) error {
+		c.Name = "z"
+		return errors.New("update error")
+	})
+	if err == nil || store.Get() != before || before.Name == "z" {
+		t.Fatal("failed update should not be published")
+	}
+}
@@ -0,0 +1,333 @@
+// Copyright 2024 Kirill Scherba <kirill@scherba.ru>. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Config helper go package. Subscribe module notifies subscribers about config
+// field value changes.
+
+package conf
+
+import (
+	"context"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Change describes a config field value change.
+type Change struct {
+	Path string // Field path
+	Old  any    // Old field value
+	New  any    // New field value
+}
+
+// subscription is a field change subscription.
+type subscription struct {
+	target any      // Subscribed object, keeps it from garbage collection
+	path   []string // Field path segments
+	f      func(Change)
+}
+
+// subscriptionKey is the key of subscribed object. The type is a part of the
+// key because a struct and its first field have the same address.
+type subscriptionKey struct {
+	typ  reflect.Type
+	addr uintptr
+}
+
+// subscriptions keeps subscriptions by the subscribed object type and address.
+var subscriptions = struct {
+	sync.RWMutex
+	m    map[subscriptionKey]map[int]*subscription
+	next int
+}{m: make(map[subscriptionKey]map[int]*subscription)}
+
+// Subscribe adds the function which is called when the field value of the
+// target object changes. The target is a pointer to a struct, a map, a pointer
+// to JSONC Document or a Store.
+//
+// The path is a field name as in Field.Name. Nested fields are separated with
+// "/" or ".", the subscriber of the field gets changes of its nested fields
+// too. Struct fields are found by Go name, json tag name or Go name ignoring
+// case as in Get. The change of the nested field is reported with the path of
+// the subscription made of Go names separated with "/", e.g. "Database/Pool".
+// The empty path subscribes to all fields of the target.
+//
+// Changes are reported when the value is changed with Field.SetValue or
+// Fields.SetValues (so form saves are reported too), when the config file is
+// reloaded by Watch and when the Store is updated. The function is called from
+// the goroutine which changed the value.
+//
+// Subscribe returns the function which removes the subscription.
+func Subscribe(target any, path string, f func(Change)) (unsubscribe func()) {
+	key, ok := targetKey(target)
+	if !ok {
+		panic("target parameter of Subscribe should be a pointer or a map")
+	}
+	sub := &subscription{target, resolvePath(configType(target),
+		splitPath(path)), f}
+
+	subscriptions.Lock()
+	defer subscriptions.Unlock()
+	id := subscriptions.next
+	subscriptions.next++
+	if subscriptions.m[key] == nil {
+		subscriptions.m[key] = make(map[int]*subscription)
+	}
+	subscriptions.m[key][id] = sub
+
+	return func() {
+		subscriptions.Lock()
+		defer subscriptions.Unlock()
+		delete(subscriptions.m[key], id)
+		if len(subscriptions.m[key]) == 0 {
+			delete(subscriptions.m, key)
+		}
+	}
+}
+
+// SubscribeContext is like Subscribe but the subscription is removed when the
+// context is done.
+func SubscribeContext(ctx context.Context, target any, path string,
+	f func(Change)) {
+
+	unsubscribe := Subscribe(target, path, f)
+	context.AfterFunc(ctx, unsubscribe)
+}
+
+// Changes subscribes to the field changes like Subscribe and returns the
+// channel which receives the changes. The subscription is removed and the
+// channel is closed when the context is done. The goroutine which changes the
+// value waits until the change is received or the context is done.
+func Changes(ctx context.Context, target any, path string) <-chan Change {
+	ch := make(chan Change)
+
+	var mu sync.Mutex
+	var closed bool
+	unsubscribe := Subscribe(target, path, func(c Change) {
+		mu.Lock()
+		defer mu.Unlock()
+		if closed {
+			return
+		}
+		select {
+		case ch <- c:
+		case <-ctx.Done():
+		}
+	})
+
+	context.AfterFunc(ctx, func() {
+		unsubscribe()
+		mu.Lock()
+		defer mu.Unlock()
+		closed = true
+		close(ch)
+	})
+	return ch
+}
+
+// notify calls subscribers of the changed fields.
+func notify(target any, changes ...Change) {
+	if len(changes) == 0 {
+		return
+	}
+
+	key, ok := targetKey(target)
+	if !ok {
+		return
+	}
+
+	// Get target subscriptions
+	subscriptions.RLock()
+	subs := make([]*subscription, 0, len(subscriptions.m[key]))
+	for _, sub := range subscriptions.m[key] {
+		subs = append(subs, sub)
+	}
+	subscriptions.RUnlock()
+
+	for _, c := range changes {
+		path := splitPath(c.Path)
+		for _, sub := range subs {
+			switch {
+			case hasPathPrefix(path, sub.path):
+				sub.f(c)
+			case hasPathPrefix(sub.path, path):
+				if nested, ok := nestedChange(c, sub.path,
+					sub.path[len(path):]); ok {
+					sub.f(nested)
+				}
+			}
+		}
+	}
+}
+
+// nestedChange returns the change of the nested field of the changed field c
+// by the path segments relative to c, and true if the nested field value is
+// changed. The returned change has the subscription path.
+func nestedChange(c Change, path, segs []string) (nested Change, ok bool) {
+	nested = Change{strings.Join(path, "/"), nestedValue(c.Old, segs),
+		nestedValue(c.New, segs)}
+	return nested, !reflect.DeepEqual(nested.Old, nested.New)
+}
+
+// nestedValue returns the nested value by path segments or nil if it does not
+// exist.
+func nestedValue(val any, segs []string) any {
+	if val == nil {
+		return nil
+	}
+	v, err := getPath(reflect.ValueOf(val), segs)
+	if err != nil || !v.IsValid() || !v.CanInterface() {
+		return nil
+	}
+	return v.Interface()
+}
+
+// configType returns the type of the config value of the subscription target.
+func configType(target any) reflect.Type {
+	if s, ok := target.(interface{ valueType() reflect.Type }); ok {
+		return s.valueType()
+	}
+	return reflect.TypeOf(target)
+}
+
+// resolvePath replaces struct field names of the path segments by Go names.
+// Fields are found in the type t the same way as in Get.
+func resolvePath(t reflect.Type, segs []string) []string {
+	path := make([]string, len(segs))
+	for i, seg := range segs {
+		path[i] = seg
+		for t != nil && t.Kind() == reflect.Ptr {
+			t = t.Elem()
+		}
+		switch {
+		case t == nil:
+		case t.Kind() == reflect.Struct:
+			f, ok := planOf(t).lookup(seg)
+			if !ok {
+				t = nil
+				continue
+			}
+			path[i], t = f.name, f.typ
+		case t.Kind() == reflect.Map || t.Kind() == reflect.Slice ||
+			t.Kind() == reflect.Array:
+			t = t.Elem()
+		default:
+			t = nil
+		}
+	}
+	return path
+}
+
+// subscribed checks if the target has subscribers.
+func subscribed(target any) bool {
+	key, ok := targetKey(target)
+	if !ok {
+		return false
+	}
+	subscriptions.RLock()
+	defer subscriptions.RUnlock()
+	return len(subscriptions.m[key]) > 0
+}
+
+// targetKey returns the key of subscribed object: type and address of the
+// pointer or the map, and true if the target is a pointer or a map. Pointer to
+// a map and the map have the same key.
+func targetKey(target any) (key subscriptionKey, ok bool) {
+	v := reflect.ValueOf(target)
+	if v.Kind() == reflect.Ptr && !v.IsNil() && v.Elem().Kind() == reflect.Map {
+		v = v.Elem()
+	}
+	switch v.Kind() {
+	case reflect.Ptr, reflect.Map:
+		return subscriptionKey{v.Type(), v.Pointer()}, true
+	}
+	return
+}
+
+// fieldValue returns the field value of the object p by field name.
+func fieldValue(p any, name string) (val any) {
+	switch {
+	case isDocument(p):
+		val, _ = p.(*Document).Get(name)
+	case isStructPtr(p):
+		v := reflect.ValueOf(p).Elem()
+		if f, ok := planOf(v.Type()).field(name); ok {
+			val = v.Field(f.index).Interface()
+		} else if v := v.FieldByName(name); v.IsValid() && v.CanInterface() {
+			val = v.Interface()
+		}
+	case isMap(p):
+		val = p.(map[string]any)[name]
+	case isMapPtr(p):
+		val = (*p.(*map[string]any))[name]
+	}
+	return
+}
+
+// fieldChanges returns changes of the top-level fields of a and b. The a and b
+// are structs, maps or pointers to them of the same type.
+func fieldChanges(a, b any) (changes []Change) {
+	va, vb := reflect.Indirect(reflect.ValueOf(a)),
+		reflect.Indirect(reflect.ValueOf(b))
+
+	switch va.Kind() {
+	case reflect.Struct:
+		for i := 0; i < va.NumField(); i++ {
+			fa, fb := va.Field(i), vb.Field(i)
+			if !fa.CanInterface() {
+				continue
+			}
+			if !reflect.DeepEqual(fa.Interface(), fb.Interface()) {
+				changes = append(changes, Change{va.Type().Field(i).Name,
+					fa.Interface(), fb.Interface()})
+			}
+		}
+
+	case reflect.Map:
+		keys := make(map[string]bool)
+		for _, v := range []reflect.Value{va, vb} {
+			for _, key := range v.MapKeys() {
+				keys[key.String()] = true
+			}
+		}
+		for key := range keys {
+			var old, cur any
+			if v := va.MapIndex(reflect.ValueOf(key)); v.IsValid() {
+				old = v.Interface()
+			}
+			if v := vb.MapIndex(reflect.ValueOf(key)); v.IsValid() {
+				cur = v.Interface()
+			}
+			if !reflect.DeepEqual(old, cur) {
+				changes = append(changes, Change{key, old, cur})
+			}
+		}
+		sort.Slice(changes, func(i, j int) bool {
+			return changes[i].Path < changes[j].Path
+		})
+	}
+	return
+}
+
+// splitPath splits the field path to segments. Segments are separated with "/"
+// or ".".
+func splitPath(path string) []string {
+	return strings.FieldsFunc(path, func(r rune) bool {
+		return r == '/' || r == '.'
+	})
+}
+
+// hasPathPrefix checks if the path starts with the prefix segments.
+func hasPathPrefix(path, prefix []string) bool {
+	if len(prefix) > len(path) {
+		return false
+	}
+	for i := range prefix {
+		if path[i] != prefix[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,148 @@
+package conf
+
+import (
+	"context"
+	"testing"
+)
+
+func TestSubscribe(t *testing.T) {
+
+	type config struct {
+		LogLevel  string
+		RateLimit int
+	}
+	c := &config{"info", 10}
+	fields := GetFields(*c, func(field *Field[any]) {})
+
+	var changes []Change
+	unsubscribe := Subscribe(c, "LogLevel", func(ch Change) {
+		changes = append(changes, ch)
+	})
+
+	// Only subscribed field changes are reported
+	for _, field := range fields {
+		switch field.Name {
+		case "LogLevel":
+			field.SetValue(c, "debug")
+		case "RateLimit":
+			field.SetValue(c, "20")
+		}
+	}
+	if len(changes) != 1 || changes[0].Old != "info" ||
+		changes[0].New != "debug" {
+		t.Fatalf("wrong changes: %v", changes)
+	}
+
+	// Unchanged value is not reported
+	fields[0].SetValue(c, "debug")
+	if len(changes) != 1 {
+		t.Fatalf("unchanged value should not be reported: %v", changes)
+	}
+
+	unsubscribe()
+	fields[0].SetValue(c, "warn")
+	if len(changes) != 1 {
+		t.Fatal("unsubscribed function should not be called")
+	}
+
+	// Store changes with channel
+	store := NewStore(config{"info", 10})
+	ctx, cancel := context.WithCancel(context.Background())
+	ch := Changes(ctx, store, "RateLimit")
+	go store.Update(func(c *config) error {
+		c.LogLevel = "error"
+		c.RateLimit = 30
+		return nil
+	})
+	if change := <-ch; change.Path != "RateLimit" || change.Old != 10 ||
+		change.New != 30 {
+		t.Fatalf("wrong store change: %v", change)
+	}
+	cancel()
+	if _, ok := <-ch; ok {
+		t.Fatal("channel should be closed")
+	}
+}
+
+func TestSubscribeFirstField(t *testing.T) {
+
+	type inner struct{ B string }
+	type config struct {
+		A inner
+		C string
+	}
+	var c config
+
+	// The struct and its first field have the same address
+	var changes []Change
+	defer Subscribe(&c, "", func(ch Change) {
+		changes = append(changes, ch)
+	})()
+
+	field := &Field[any]{Name: "B", Type: "string"}
+	if err := field.SetValue(&c.A, "b"); err != nil {
+		t.Fatal(err)
+	}
+	if c.A.B != "b" || len(changes) != 0 {
+		t.Fatalf("field change should not notify struct subscribers: %v",
+			changes)
+	}
+}
+
+func TestSubscribeNested(t *testing.T) {
+
+	type pool struct {
+		Max int `json:"max"`
+	}
+	type config struct {
+		Database struct {
+			Host string `json:"host"`
+			Pool pool   `json:"pool"`
+		} `json:"database"`
+	}
+	var c config
+
+	// Nested fields are subscribed by Go or json tag names
+	var changes []Change
+	defer Subscribe(&c, "database.pool", func(ch Change) {
+		changes = append(changes, ch)
+	})()
+	var maxChanges []Change
+	defer Subscribe(&c, "Database/Pool/Max", func(ch Change) {
+		maxChanges = append(maxChanges, ch)
+	})()
+
+	if err := Set(&c, "/database/pool/max", "10"); err != nil {
+		t.Fatal(err)
+	}
+	if err := Set(&c, "database.host", "a"); err != nil {
+		t.Fatal(err)
+	}
+	if len(changes) != 1 || changes[0].Path != "Database/Pool" ||
+		changes[0].Old != (pool{}) || changes[0].New != (pool{10}) {
+		t.Fatalf("wrong changes: %v", changes)
+	}
+	if len(maxChanges) != 1 || maxChanges[0].Path != "Database/Pool/Max" ||
+		maxChanges[0].Old != 0 || maxChanges[0].New != 10 {
+		t.Fatalf("wrong max changes: %v", maxChanges)
+	}
+
+	// SetValues reports nested changes too
+	db := c.Database
+	db.Host, db.Pool.Max = "b", 20
+	fields := GetFields(c, func(field *Field[any]) {})
+	err := fields.SetValues(&c, func(field *Field[any]) (string, bool) {
+		field.Value = db
+		return "", false
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(changes) != 2 || changes[1].New != (pool{20}) {
+		t.Fatalf("wrong changes: %v", changes)
+	}
+	if len(maxChanges) != 2 || maxChanges[1].Old != 10 ||
+		maxChanges[1].New != 20 {
+		t.Fatalf("wrong max changes: %v", maxChanges)
+	}
+}
@@ -0,0 +1,139 @@
+// Copyright 2024 Kirill Scherba <kirill@scherba.ru>. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// CheckGroup options.
+
+package types
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"slices"
+	"strconv"
+)
+
+// CheckGroup type. It is a set of selected options stored in the config file
+// as JSON array of selected values. The options and the selection limits are
+// not stored, they are set in the default value or taken from the "options",
+// "min" and "max" struct field tags:
+//
+//	Modules types.CheckGroup `json:"modules" options:"auth,api,web" min:"1"`
+type CheckGroup struct {
+	Options    []string // Options to select from
+	Horizontal bool     // Options are placed horizontally
+	Min        int      // Minimum number of selected options
+	Max        int      // Maximum number of selected options, zero is unlimited
+	Selected   []string // Selected options
+}
+
+// GetOptions returns the options of the check group.
+func (o CheckGroup) GetOptions() []string { return o.Options }
+
+// GetSelected returns the selected options of the check group.
+func (o CheckGroup) GetSelected() []string { return o.Selected }
+
+// GetValue returns the selected options encoded as JSON array.
+func (o CheckGroup) GetValue() string {
+	data, _ := json.Marshal(o.selected())
+	return string(data)
+}
+
+// SetValue sets the selected options from JSON array.
+func (o CheckGroup) SetValue(val string) CheckGroup {
+	o.Selected = nil
+	json.Unmarshal([]byte(val), &o.Selected)
+	return o
+}
+
+// SetOptions sets the options of the check group.
+func (o *CheckGroup) SetOptions(options []string) { o.Options = options }
+
+// SetSelected sets the selected options of the check group.
+func (o *CheckGroup) SetSelected(selected []string) { o.Selected = selected }
+
+// SetLimits sets the minimum and maximum number of selected options. The zero
+// maximum is unlimited.
+func (o *CheckGroup) SetLimits(min, max int) { o.Min, o.Max = min, max }
+
+// SetHorizontal sets the horizontal type of the check group type.
+func (o *CheckGroup) SetHorizontal() { o.Horizontal = true }
+
+// SetVertical sets the vertical type of the check group type.
+func (o *CheckGroup) SetVertical() { o.Horizontal = false }
+
+// MarshalJSON encodes the selected options as JSON array.
+func (o CheckGroup) MarshalJSON() ([]byte, error) {
+	return json.Marshal(o.selected())
+}
+
+// UnmarshalJSON decodes the selected options from JSON array. The options and
+// the limits are not changed.
+func (o *CheckGroup) UnmarshalJSON(data []byte) error {
+	o.Selected = nil
+	return json.Unmarshal(data, &o.Selected)
+}
+
+// Describe returns the check group field descriptor.
+func (o CheckGroup) Describe() Descriptor { return o.DescribeTag("") }
+
+// DescribeTag returns the check group field descriptor. The options and the
+// limits are taken from the struct field tag if set.
+func (o CheckGroup) DescribeTag(tag reflect.StructTag) Descriptor {
+	o = o.withTag(tag)
+	return Descriptor{
+		Kind:       KindCheckGroup,
+		Options:    o.Options,
+		Horizontal: o.Horizontal,
+		Validate:   func(s string) error { return o.SetValue(s).Validate() },
+	}
+}
+
+// Validate checks the selected options are in options and the number of
+// selected options is within limits.
+func (o CheckGroup) Validate() error {
+	for _, s := range o.Selected {
+		if len(o.Options) > 0 && !slices.Contains(o.Options, s) {
+			return fmt.Errorf("%q is not one of options", s)
+		}
+	}
+	switch n := len(o.Selected); {
+	case n < o.Min:
+		return fmt.Errorf("at least %d options should be selected", o.Min)
+	case o.Max > 0 && n > o.Max:
+		return fmt.Errorf("at most %d options should be selected", o.Max)
+	}
+	return nil
+}
+
+// ValidateTag checks the selected options like Validate with the options and
+// the limits taken from the struct field tag if set.
+func (o CheckGroup) ValidateTag(tag reflect.StructTag) error {
+	return o.withTag(tag).Validate()
+}
+
+// withTag returns the check group with the options and the limits taken from
+// the struct field tag if set.
+func (o CheckGroup) withTag(tag reflect.StructTag) CheckGroup {
+	if opts := TagOptions(tag); opts != nil {
+		o.Options = opts
+	}
+	for _, limit := range []struct {
+		name string
+		v    *int
+	}{{"min", &o.Min}, {"max", &o.Max}} {
+		if n, err := strconv.Atoi(tag.Get(limit.name)); err == nil {
+			*limit.v = n
+		}
+	}
+	return o
+}
+
+// selected returns the selected options, empty slice instead of nil.
+func (o CheckGroup) selected() []string {
+	if o.Selected == nil {
+		return []string{}
+	}
+	return o.Selected
+}
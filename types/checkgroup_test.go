@@ -0,0 +1,62 @@
+package types
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/teonet-go/conf"
+)
+
+func TestCheckGroup(t *testing.T) {
+
+	type config struct {
+		Modules CheckGroup `options:"auth,api,web" min:"1" max:"2"`
+	}
+
+	// Options and limits are taken from the tag
+	for _, test := range []struct {
+		selected []string
+		valid    bool
+	}{
+		{[]string{"auth"}, true},
+		{[]string{"web", "auth"}, true},
+		{nil, false},
+		{[]string{"db"}, false},
+		{[]string{"auth", "api", "web"}, false},
+	} {
+		c := config{CheckGroup{Selected: test.selected}}
+		if err := conf.Validate(c); (err == nil) != test.valid {
+			t.Fatalf("%v: got error %v, want valid %v", test.selected, err,
+				test.valid)
+		}
+	}
+	d := CheckGroup{}.DescribeTag(`options:"a,b" max:"1"`)
+	if !reflect.DeepEqual(d.Options, []string{"a", "b"}) ||
+		d.Validate(`["a"]`) != nil || d.Validate(`["a","b"]`) == nil {
+		t.Fatalf("wrong check group descriptor %+v", d)
+	}
+
+	// Only the selected options are saved in their order
+	c := config{CheckGroup{Options: []string{"a"}, Min: 1}}
+	if err := json.Unmarshal([]byte(`{"Modules":["web","auth"]}`),
+		&c); err != nil {
+		t.Fatal(err)
+	}
+	if c.Modules.Min != 1 || len(c.Modules.Options) != 1 {
+		t.Fatal("options and limits should not be changed by unmarshal")
+	}
+	data, err := json.Marshal(c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := `{"Modules":["web","auth"]}`; string(data) != want {
+		t.Fatalf("got %s, want %s", data, want)
+	}
+	if v := c.Modules.SetValue(`["api"]`).GetValue(); v != `["api"]` {
+		t.Fatalf("wrong selected options %s", v)
+	}
+	if v := (CheckGroup{}).GetValue(); v != `[]` {
+		t.Fatalf("empty check group should be [], got %s", v)
+	}
+}
@@ -0,0 +1,172 @@
+// Copyright 2024 Kirill Scherba <kirill@scherba.ru>. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Color entry.
+
+package types
+
+import (
+	"fmt"
+	"image/color"
+	"strconv"
+	"strings"
+)
+
+// Color type. It is a color in "#RRGGBB" or "#RRGGBBAA" hex form, "rgb(r, g,
+// b)" or "rgba(r, g, b, a)" functional form, or a named color like "red". The
+// color is saved in canonical lower case "#rrggbb" form, or "#rrggbbaa" if it
+// is not opaque.
+type Color string
+
+// NewColor returns the color c in canonical form.
+func NewColor(c color.Color) Color {
+	return formatColor(color.NRGBAModel.Convert(c).(color.NRGBA))
+}
+
+// GetValue returns the color.
+func (c Color) GetValue() string { return string(c) }
+
+// SetValue sets the color in canonical form. Invalid color is set as is.
+func (c Color) SetValue(val string) Color {
+	return Color(strings.TrimSpace(val)).Canonical()
+}
+
+// Parse parses the color.
+func (c Color) Parse() (color.NRGBA, error) {
+	s := strings.ToLower(strings.TrimSpace(string(c)))
+	switch {
+	case strings.HasPrefix(s, "#"):
+		return parseHexColor(s)
+	case strings.HasPrefix(s, "rgb"):
+		return parseRGBColor(s)
+	}
+	if rgb, ok := namedColors[s]; ok {
+		return color.NRGBA{uint8(rgb >> 16), uint8(rgb >> 8), uint8(rgb), 0xff},
+			nil
+	}
+	if s == "transparent" {
+		return color.NRGBA{}, nil
+	}
+	return color.NRGBA{}, fmt.Errorf("invalid color %q", string(c))
+}
+
+// Canonical returns the color in canonical form. Invalid color is returned as
+// is.
+func (c Color) Canonical() Color {
+	nrgba, err := c.Parse()
+	if err != nil {
+		return c
+	}
+	return formatColor(nrgba)
+}
+
+// MarshalText encodes the color in canonical form.
+func (c Color) MarshalText() ([]byte, error) {
+	return []byte(c.Canonical()), nil
+}
+
+// Describe returns the color field descriptor.
+func (c Color) Describe() Descriptor {
+	return Descriptor{
+		Kind:        KindColor,
+		Placeholder: "#rrggbb",
+		Validate:    func(s string) error { return Color(s).Validate() },
+	}
+}
+
+// Validate checks the color. The empty color is valid.
+func (c Color) Validate() error {
+	if strings.TrimSpace(string(c)) == "" {
+		return nil
+	}
+	_, err := c.Parse()
+	return err
+}
+
+// parseHexColor parses the "#rgb", "#rrggbb" or "#rrggbbaa" color.
+func parseHexColor(s string) (c color.NRGBA, err error) {
+	hex := s[1:]
+	if len(hex) == 3 {
+		hex = string([]byte{hex[0], hex[0], hex[1], hex[1], hex[2], hex[2]})
+	}
+	if len(hex) == 6 {
+		hex += "ff"
+	}
+	n, perr := strconv.ParseUint(hex, 16, 32)
+	if len(hex) != 8 || perr != nil {
+		err = fmt.Errorf("invalid color %q, #RRGGBB or #RRGGBBAA expected", s)
+		return
+	}
+	return color.NRGBA{uint8(n >> 24), uint8(n >> 16), uint8(n >> 8),
+		uint8(n)}, nil
+}
+
+// parseRGBColor parses the "rgb(r, g, b)" or "rgba(r, g, b, a)" color. Color
+// components are from 0 to 255 and alpha is from 0 to 1.
+func parseRGBColor(s string) (c color.NRGBA, err error) {
+	err = fmt.Errorf("invalid color %q, rgb(r, g, b) or rgba(r, g, b, a) "+
+		"expected", s)
+
+	name, args, ok := strings.Cut(s, "(")
+	if !ok || !strings.HasSuffix(args, ")") {
+		return
+	}
+	parts := strings.Split(strings.TrimSuffix(args, ")"), ",")
+	if name == "rgb" && len(parts) != 3 || name == "rgba" && len(parts) != 4 ||
+		name != "rgb" && name != "rgba" {
+		return
+	}
+
+	var rgb [3]uint8
+	for i := range rgb {
+		n, perr := strconv.ParseUint(strings.TrimSpace(parts[i]), 10, 8)
+		if perr != nil {
+			return
+		}
+		rgb[i] = uint8(n)
+	}
+	alpha := 1.0
+	if len(parts) == 4 {
+		a, perr := strconv.ParseFloat(strings.TrimSpace(parts[3]), 64)
+		if perr != nil || a < 0 || a > 1 {
+			return
+		}
+		alpha = a
+	}
+	return color.NRGBA{rgb[0], rgb[1], rgb[2], uint8(alpha*255 + 0.5)}, nil
+}
+
+// formatColor returns the color in canonical form.
+func formatColor(c color.NRGBA) Color {
+	if c.A == 0xff {
+		return Color(fmt.Sprintf("#%02x%02x%02x", c.R, c.G, c.B))
+	}
+	return Color(fmt.Sprintf("#%02x%02x%02x%02x", c.R, c.G, c.B, c.A))
+}
+
+// namedColors contains basic named colors.
+var namedColors = map[string]uint32{
+	"black":   0x000000,
+	"silver":  0xc0c0c0,
+	"gray":    0x808080,
+	"grey":    0x808080,
+	"white":   0xffffff,
+	"maroon":  0x800000,
+	"red":     0xff0000,
+	"purple":  0x800080,
+	"fuchsia": 0xff00ff,
+	"magenta": 0xff00ff,
+	"green":   0x008000,
+	"lime":    0x00ff00,
+	"olive":   0x808000,
+	"yellow":  0xffff00,
+	"navy":    0x000080,
+	"blue":    0x0000ff,
+	"teal":    0x008080,
+	"aqua":    0x00ffff,
+	"cyan":    0x00ffff,
+	"orange":  0xffa500,
+	"brown":   0xa52a2a,
+	"pink":    0xffc0cb,
+}
@@ -0,0 +1,51 @@
+package types
+
+import (
+	"encoding/json"
+	"image/color"
+	"testing"
+)
+
+func TestColor(t *testing.T) {
+
+	for _, test := range []struct {
+		value string
+		want  Color
+		valid bool
+	}{
+		{"", "", true},
+		{" #ABC ", "#aabbcc", true},
+		{"#AABBCC", "#aabbcc", true},
+		{"#aabbcc80", "#aabbcc80", true},
+		{"#aabbccff", "#aabbcc", true},
+		{"rgb(255, 0, 0)", "#ff0000", true},
+		{"rgba(255, 0, 0, 0.5)", "#ff000080", true},
+		{"Red", "#ff0000", true},
+		{"transparent", "#00000000", true},
+		{"#12345", "#12345", false},
+		{"rgb(256, 0, 0)", "rgb(256, 0, 0)", false},
+		{"bad", "bad", false},
+	} {
+		c := Color("").SetValue(test.value)
+		if c != test.want {
+			t.Fatalf("%q: got %q, want %q", test.value, c, test.want)
+		}
+		if err := c.Validate(); (err == nil) != test.valid {
+			t.Fatalf("%q: got error %v, want valid %v", test.value, err,
+				test.valid)
+		}
+	}
+
+	if c := NewColor(color.RGBA{0, 0x80, 0xff, 0xff}); c != "#0080ff" {
+		t.Fatalf("got color %q, want #0080ff", c)
+	}
+
+	// Colors are saved in canonical form
+	data, err := json.Marshal(struct{ A, B Color }{"#FFF", "bad"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := `{"A":"#ffffff","B":"bad"}`; string(data) != want {
+		t.Fatalf("got %s, want %s", data, want)
+	}
+}
@@ -0,0 +1,87 @@
+// Copyright 2024 Kirill Scherba <kirill@scherba.ru>. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Deprecated Fyne widget functions of special field types.
+
+package types
+
+import (
+	"fyne.io/fyne/v2"
+	"github.com/teonet-go/conf"
+	"github.com/teonet-go/conf/internal/compat"
+)
+
+// CheckWidget creates and returns widget and true if the field type is a
+// registered special field type.
+//
+// Deprecated: Use fyne/form package which creates widgets from the field
+// descriptors. CheckWidget delegates to fyne/form and returns false if the
+// fyne/form package is not imported.
+func CheckWidget(field *conf.Field[fyne.CanvasObject]) (w fyne.CanvasObject,
+	h, ok bool) {
+
+	if compat.NewWidget == nil {
+		return
+	}
+	return compat.NewWidget(field)
+}
+
+// CheckSave checks if the field type is a registered special field type, gets
+// widget value and sets it to field using SetValue.
+//
+// Deprecated: Use fyne/form package which saves widget values, or
+// SetFieldValue. CheckSave delegates to fyne/form and returns false if the
+// fyne/form package is not imported.
+func CheckSave(field *conf.Field[fyne.CanvasObject]) (ok bool) {
+	if compat.WidgetValue == nil {
+		return
+	}
+	val, ok := compat.WidgetValue(field)
+	if !ok {
+		return
+	}
+	return SetFieldValue(field, val)
+}
+
+// NewWidget creates and returns widget and true if the field type is supported.
+//
+// Deprecated: Use fyne/form package which creates widgets from the field
+// descriptors. NewWidget delegates to fyne/form and returns false if the
+// fyne/form package is not imported.
+func NewWidget[T Types[T], F any](field *conf.Field[F]) (fyne.CanvasObject,
+	bool) {
+
+	w, _, ok := CheckWidget(widgetField(field, field.Value))
+	return w, ok
+}
+
+// GetWidgetValue returns the widget value.
+//
+// Deprecated: Use fyne/form package which saves widget values.
+// GetWidgetValue delegates to fyne/form and returns empty string if the
+// fyne/form package is not imported.
+func GetWidgetValue[T Types[T]](val T, field *conf.Field[fyne.CanvasObject]) string {
+	if compat.WidgetValue == nil {
+		return ""
+	}
+	s, _ := compat.WidgetValue(widgetField(field, val))
+	return s
+}
+
+// widgetField returns the copy of the field with the value to create or read
+// the field widget.
+func widgetField[F any](field *conf.Field[F], value any) *conf.Field[fyne.CanvasObject] {
+	f := &conf.Field[fyne.CanvasObject]{
+		NameDisplay: field.NameDisplay,
+		Name:        field.Name,
+		Type:        field.Type,
+		ValueStr:    field.ValueStr,
+		Value:       value,
+		Tag:         field.Tag,
+	}
+	if w, ok := any(field.Entry).(fyne.CanvasObject); ok {
+		f.Entry = w
+	}
+	return f
+}
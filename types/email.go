@@ -7,10 +7,8 @@
 package types
 
 import (
-	"fyne.io/fyne/v2"
-	"fyne.io/fyne/v2/data/validation"
-	"fyne.io/fyne/v2/widget"
-	"github.com/teonet-go/conf"
+	"fmt"
+	"net/mail"
 )
 
 // Email type.
@@ -26,17 +24,24 @@ func (p Email) SetValue(val string) Email {
 	return Email(val)
 }
 
-// GetWidgetValue returns the widget value.
-func (p Email) GetWidgetValue(field *conf.Field[fyne.CanvasObject]) string {
-	return field.Entry.(*widget.Entry).Text
+// Describe returns the email field descriptor.
+func (p Email) Describe() Descriptor {
+	return Descriptor{
+		Kind:        KindEmail,
+		Placeholder: "test@example.com",
+		Hint:        true,
+		Validate:    func(s string) error { return p.SetValue(s).Validate() },
+	}
 }
 
-// NewWidget creates and returns widget and true if hint for this field is
-// supported.
-func (p Email) NewWidget() (fyne.CanvasObject, bool) {
-	w := widget.NewEntry()
-	w.SetPlaceHolder("test@example.com")
-	w.SetText(GetValue(p))
-	w.Validator = validation.NewRegexp(`\w{1,}@\w{1,}\.\w{1,4}`, "not a valid email")
-	return w, true
+// Validate checks the email is a valid RFC 5322 address. The empty email is
+// valid.
+func (p Email) Validate() error {
+	if p == "" {
+		return nil
+	}
+	if _, err := mail.ParseAddress(string(p)); err != nil {
+		return fmt.Errorf("not a valid email: %w", err)
+	}
+	return nil
 }
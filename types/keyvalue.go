@@ -0,0 +1,192 @@
+// Copyright 2024 Kirill Scherba <kirill@scherba.ru>. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Key and value pairs table.
+
+package types
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"regexp"
+)
+
+// Pair is a key and value pair of KeyValue.
+type Pair struct {
+	Key   string
+	Value string
+}
+
+// KeyValue type. It is an ordered list of string key and value pairs, e.g.
+// HTTP headers, labels or environment variables, stored in the config file as
+// JSON object with members in the list order. Keys should be unique and not
+// empty. The keys may be restricted with the regular expression which should
+// match the whole key. The pattern is not stored, it is set in the default
+// value or taken from the "pattern" struct field tag:
+//
+//	Headers types.KeyValue `json:"headers" pattern:"[A-Za-z0-9-]+"`
+type KeyValue struct {
+	Pattern string // Key regular expression, empty matches any key
+	Pairs   []Pair // Key and value pairs
+}
+
+// Get returns the value by key and true if the key exists.
+func (kv KeyValue) Get(key string) (value string, ok bool) {
+	for _, p := range kv.Pairs {
+		if p.Key == key {
+			return p.Value, true
+		}
+	}
+	return
+}
+
+// Set sets the value by key. The new key is appended to the end of the list.
+func (kv *KeyValue) Set(key, value string) {
+	for i := range kv.Pairs {
+		if kv.Pairs[i].Key == key {
+			kv.Pairs[i].Value = value
+			return
+		}
+	}
+	kv.Pairs = append(kv.Pairs, Pair{key, value})
+}
+
+// Delete removes the key and its value.
+func (kv *KeyValue) Delete(key string) {
+	for i := range kv.Pairs {
+		if kv.Pairs[i].Key == key {
+			kv.Pairs = append(kv.Pairs[:i:i], kv.Pairs[i+1:]...)
+			return
+		}
+	}
+}
+
+// Map returns the pairs as map.
+func (kv KeyValue) Map() map[string]string {
+	m := make(map[string]string, len(kv.Pairs))
+	for _, p := range kv.Pairs {
+		m[p.Key] = p.Value
+	}
+	return m
+}
+
+// GetValue returns the pairs encoded as JSON object.
+func (kv KeyValue) GetValue() string {
+	data, _ := kv.MarshalJSON()
+	return string(data)
+}
+
+// SetValue sets the pairs from JSON object. Duplicate keys are kept to be
+// reported by Validate.
+func (kv KeyValue) SetValue(val string) KeyValue {
+	kv.UnmarshalJSON([]byte(val))
+	return kv
+}
+
+// MarshalJSON encodes the pairs as JSON object with members in the list
+// order.
+func (kv KeyValue) MarshalJSON() ([]byte, error) {
+	var b bytes.Buffer
+	b.WriteByte('{')
+	for i, p := range kv.Pairs {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		key, _ := json.Marshal(p.Key)
+		value, _ := json.Marshal(p.Value)
+		b.Write(key)
+		b.WriteByte(':')
+		b.Write(value)
+	}
+	b.WriteByte('}')
+	return b.Bytes(), nil
+}
+
+// UnmarshalJSON decodes the pairs from JSON object keeping the members order.
+// Member values should be strings, null value is decoded as empty string. The
+// pattern is not changed, JSON null does not change the pairs.
+func (kv *KeyValue) UnmarshalJSON(data []byte) (err error) {
+	if string(bytes.TrimSpace(data)) == "null" {
+		return
+	}
+	kv.Pairs = nil
+	dec := json.NewDecoder(bytes.NewReader(data))
+	if t, err := dec.Token(); err != nil || t != json.Delim('{') {
+		return errors.New("key value pairs should be JSON object")
+	}
+	for dec.More() {
+		t, err := dec.Token()
+		if err != nil {
+			return err
+		}
+		key, _ := t.(string)
+		var value *string
+		if err = dec.Decode(&value); err != nil {
+			return fmt.Errorf("value of %q should be a string", key)
+		}
+		p := Pair{Key: key}
+		if value != nil {
+			p.Value = *value
+		}
+		kv.Pairs = append(kv.Pairs, p)
+	}
+	_, err = dec.Token()
+	return
+}
+
+// Describe returns the key value pairs field descriptor.
+func (kv KeyValue) Describe() Descriptor { return kv.DescribeTag("") }
+
+// DescribeTag returns the key value pairs field descriptor. The key pattern is
+// taken from the struct field tag if set.
+func (kv KeyValue) DescribeTag(tag reflect.StructTag) Descriptor {
+	kv = kv.withTag(tag)
+	return Descriptor{
+		Kind:     KindKeyValue,
+		Validate: func(s string) error { return kv.SetValue(s).Validate() },
+	}
+}
+
+// Validate checks the keys are not empty, unique and match the pattern.
+func (kv KeyValue) Validate() error {
+	var re *regexp.Regexp
+	if kv.Pattern != "" {
+		var err error
+		if re, err = regexp.Compile("^(?:" + kv.Pattern + ")$"); err != nil {
+			return fmt.Errorf("invalid key pattern: %w", err)
+		}
+	}
+	keys := make(map[string]bool, len(kv.Pairs))
+	for _, p := range kv.Pairs {
+		switch {
+		case p.Key == "":
+			return errors.New("empty key")
+		case keys[p.Key]:
+			return fmt.Errorf("duplicate key %q", p.Key)
+		case re != nil && !re.MatchString(p.Key):
+			return fmt.Errorf("key %q does not match pattern %q", p.Key,
+				kv.Pattern)
+		}
+		keys[p.Key] = true
+	}
+	return nil
+}
+
+// ValidateTag checks the keys like Validate with the key pattern taken from
+// the struct field tag if set.
+func (kv KeyValue) ValidateTag(tag reflect.StructTag) error {
+	return kv.withTag(tag).Validate()
+}
+
+// withTag returns the pairs with the key pattern taken from the struct field
+// tag if set.
+func (kv KeyValue) withTag(tag reflect.StructTag) KeyValue {
+	if pattern, ok := tag.Lookup("pattern"); ok {
+		kv.Pattern = pattern
+	}
+	return kv
+}
@@ -0,0 +1,75 @@
+package types
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/teonet-go/conf"
+)
+
+func TestKeyValue(t *testing.T) {
+
+	type config struct {
+		Headers KeyValue `pattern:"[A-Za-z-]+"`
+	}
+	data := `{"Headers":{"X-B":"2","X-A":"1","X-C":null}}`
+
+	// Round trip keeps the keys order
+	var c config
+	if err := json.Unmarshal([]byte(data), &c); err != nil {
+		t.Fatal(err)
+	}
+	want := []Pair{{"X-B", "2"}, {"X-A", "1"}, {"X-C", ""}}
+	if !reflect.DeepEqual(c.Headers.Pairs, want) {
+		t.Fatalf("got pairs %v, want %v", c.Headers.Pairs, want)
+	}
+	out, err := json.Marshal(c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := `{"Headers":{"X-B":"2","X-A":"1","X-C":""}}`; string(out) !=
+		want {
+		t.Fatalf("got %s, want %s", out, want)
+	}
+	if v := c.Headers.SetValue(`{"b":"1","a":"2"}`).GetValue(); v !=
+		`{"b":"1","a":"2"}` {
+		t.Fatalf("wrong key value pairs %s", v)
+	}
+	if err = json.Unmarshal([]byte(`null`), &c.Headers); err != nil ||
+		len(c.Headers.Pairs) != 3 {
+		t.Fatal("null should not change key value pairs")
+	}
+	for _, s := range []string{`[]`, `{"a":1}`, `{"a":"1"`} {
+		if json.Unmarshal([]byte(s), &c.Headers) == nil {
+			t.Fatalf("%s: invalid key value pairs should be an error", s)
+		}
+	}
+
+	// Keys are checked with the pattern from the tag
+	for _, test := range []struct {
+		pairs []Pair
+		valid bool
+	}{
+		{nil, true},
+		{[]Pair{{"X-Id", "1"}, {"X-Name", ""}}, true},
+		{[]Pair{{"X_Id", "1"}}, false},
+		{[]Pair{{"", "1"}}, false},
+		{[]Pair{{"X-Id", "1"}, {"X-Id", "2"}}, false},
+	} {
+		err := conf.Validate(config{KeyValue{Pairs: test.pairs}})
+		if (err == nil) != test.valid {
+			t.Fatalf("%v: got error %v, want valid %v", test.pairs, err,
+				test.valid)
+		}
+	}
+
+	// Duplicate keys are kept to be reported by Validate
+	kv := KeyValue{}.SetValue(`{"a":"1","a":"2"}`)
+	if len(kv.Pairs) != 2 || kv.Validate() == nil {
+		t.Fatal("duplicate keys should be an error")
+	}
+	if kv.ValidateTag(`pattern:"[`) == nil {
+		t.Fatal("invalid pattern should be an error")
+	}
+}
@@ -7,15 +7,15 @@
 package types
 
 import (
-	"fyne.io/fyne/v2"
-	"fyne.io/fyne/v2/widget"
-	"github.com/teonet-go/conf"
+	"fmt"
+	"strings"
 )
 
 // Password type.
 type Multiline struct {
 	Value         string `json:"value"`
 	MultiLineRows int    `json:"multiline_rows"`
+	MaxLines      int    `json:"max_lines,omitempty"` // Zero is unlimited
 }
 
 // GetValue returns the value of the password.
@@ -40,16 +40,30 @@ func (m *Multiline) SetNumRows(num int) {
 	m.MultiLineRows = num
 }
 
-// GetWidgetValue returns the widget value.
-func (p Multiline) GetWidgetValue(field *conf.Field[fyne.CanvasObject]) string {
-	return field.Entry.(*widget.Entry).Text
+// Describe returns the multiline field descriptor.
+func (m Multiline) Describe() Descriptor {
+	return Descriptor{
+		Kind:     KindMultiline,
+		Rows:     m.GetNumRows(),
+		Validate: func(s string) error { return m.SetValue(s).Validate() },
+	}
 }
 
-// NewWidget creates and returns widget and true if hint for this field is
-// supported.
-func (m Multiline) NewWidget() (fyne.CanvasObject, bool) {
-	w := widget.NewMultiLineEntry()
-	w.SetMinRowsVisible(m.GetNumRows())
-	w.SetText(GetValue(m))
-	return w, false
+// SetMaxLines sets the maximum number of text lines, zero is unlimited.
+func (m *Multiline) SetMaxLines(num int) {
+	m.MaxLines = num
+}
+
+// Validate checks the number of visible rows is not negative and the number
+// of text lines does not exceed the maximum.
+func (m Multiline) Validate() error {
+	if m.MultiLineRows < 0 {
+		return fmt.Errorf("invalid number of multiline rows %d",
+			m.MultiLineRows)
+	}
+	if lines := strings.Count(m.Value, "\n") + 1; m.MaxLines > 0 &&
+		lines > m.MaxLines {
+		return fmt.Errorf("text should have at most %d lines", m.MaxLines)
+	}
+	return nil
 }
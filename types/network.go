@@ -0,0 +1,431 @@
+// Copyright 2024 Kirill Scherba <kirill@scherba.ru>. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Network address entries.
+
+package types
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/netip"
+	"net/url"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// URL type. It is an absolute URL. The allowed schemes may be restricted with
+// the "schemes" struct field tag:
+//
+//	Server types.URL `json:"server" schemes:"http,https"`
+type URL string
+
+// GetValue returns the URL.
+func (u URL) GetValue() string { return string(u) }
+
+// SetValue sets the URL in canonical form.
+func (u URL) SetValue(val string) URL {
+	return URL(strings.TrimSpace(val)).Canonical()
+}
+
+// Parse parses the URL.
+func (u URL) Parse() (*url.URL, error) {
+	p, err := url.Parse(string(u))
+	if err != nil {
+		return nil, err
+	}
+	if p.Scheme == "" || (p.Host == "" && p.Opaque == "") {
+		return nil, fmt.Errorf("%q is not an absolute URL", string(u))
+	}
+	return p, nil
+}
+
+// Canonical returns the URL in canonical form with lower case scheme and host.
+// Invalid URL is returned as is.
+func (u URL) Canonical() URL {
+	p, err := u.Parse()
+	if err != nil {
+		return u
+	}
+	p.Host = strings.ToLower(p.Host)
+	return URL(p.String())
+}
+
+// Describe returns the URL field descriptor.
+func (u URL) Describe() Descriptor { return u.DescribeTag("") }
+
+// DescribeTag returns the URL field descriptor with the schemes allowed by the
+// struct field tag.
+func (u URL) DescribeTag(tag reflect.StructTag) Descriptor {
+	schemes := tagList(tag, "schemes")
+	placeholder := "https://example.com"
+	if len(schemes) > 0 {
+		placeholder = schemes[0] + "://example.com"
+	}
+	return Descriptor{
+		Kind:        KindURL,
+		Placeholder: placeholder,
+		Hint:        true,
+		Validate: func(s string) error {
+			return u.SetValue(s).validate(schemes)
+		},
+	}
+}
+
+// MarshalText encodes the URL in canonical form.
+func (u URL) MarshalText() ([]byte, error) {
+	return []byte(u.Canonical()), nil
+}
+
+// Validate checks the URL is absolute. The empty URL is valid.
+func (u URL) Validate() error { return u.validate(nil) }
+
+// ValidateTag checks the URL is absolute and its scheme is allowed by the
+// struct field tag.
+func (u URL) ValidateTag(tag reflect.StructTag) error {
+	return u.validate(tagList(tag, "schemes"))
+}
+
+// validate checks the URL is absolute and its scheme is allowed. Any scheme
+// is allowed if schemes is empty.
+func (u URL) validate(schemes []string) error {
+	if u == "" {
+		return nil
+	}
+	p, err := u.Parse()
+	if err != nil {
+		return err
+	}
+	if len(schemes) > 0 && !slices.Contains(schemes, p.Scheme) {
+		return fmt.Errorf("URL scheme should be one of %s",
+			strings.Join(schemes, ", "))
+	}
+	return nil
+}
+
+// IP type. It is an IPv4 or IPv6 address. The address family may be
+// restricted with the "ip" struct field tag:
+//
+//	Listen types.IP `json:"listen" ip:"v4"`
+type IP string
+
+// GetValue returns the IP address.
+func (ip IP) GetValue() string { return string(ip) }
+
+// SetValue sets the IP address in canonical form.
+func (ip IP) SetValue(val string) IP {
+	return IP(strings.TrimSpace(val)).Canonical()
+}
+
+// Parse parses the IP address.
+func (ip IP) Parse() (netip.Addr, error) { return netip.ParseAddr(string(ip)) }
+
+// Canonical returns the IP address in canonical form. Invalid address is
+// returned as is.
+func (ip IP) Canonical() IP {
+	addr, err := ip.Parse()
+	if err != nil {
+		return ip
+	}
+	return IP(addr.String())
+}
+
+// Describe returns the IP address field descriptor.
+func (ip IP) Describe() Descriptor { return ip.DescribeTag("") }
+
+// DescribeTag returns the IP address field descriptor with the address family
+// restricted by the struct field tag.
+func (ip IP) DescribeTag(tag reflect.StructTag) Descriptor {
+	family := tag.Get("ip")
+	return Descriptor{
+		Kind:        KindIP,
+		Placeholder: ipPlaceholder(family, "192.168.1.1", "2001:db8::1"),
+		Hint:        true,
+		Validate: func(s string) error {
+			return ip.SetValue(s).validate(family)
+		},
+	}
+}
+
+// MarshalText encodes the IP address in canonical form.
+func (ip IP) MarshalText() ([]byte, error) {
+	return []byte(ip.Canonical()), nil
+}
+
+// Validate checks the IP address. The empty address is valid.
+func (ip IP) Validate() error { return ip.validate("") }
+
+// ValidateTag checks the IP address and its family set in the struct field
+// tag.
+func (ip IP) ValidateTag(tag reflect.StructTag) error {
+	return ip.validate(tag.Get("ip"))
+}
+
+// validate checks the IP address and its family, "v4", "v6" or any if empty.
+func (ip IP) validate(family string) error {
+	if ip == "" {
+		return nil
+	}
+	addr, err := ip.Parse()
+	if err != nil {
+		return err
+	}
+	return checkFamily(addr, family)
+}
+
+// CIDR type. It is an IP network prefix, e.g. "10.0.0.0/8". The address
+// family may be restricted with the "ip" struct field tag.
+type CIDR string
+
+// GetValue returns the network prefix.
+func (c CIDR) GetValue() string { return string(c) }
+
+// SetValue sets the network prefix in canonical form.
+func (c CIDR) SetValue(val string) CIDR {
+	return CIDR(strings.TrimSpace(val)).Canonical()
+}
+
+// Parse parses the network prefix.
+func (c CIDR) Parse() (netip.Prefix, error) {
+	return netip.ParsePrefix(string(c))
+}
+
+// Canonical returns the network prefix in canonical form with masked host
+// bits. Invalid prefix is returned as is.
+func (c CIDR) Canonical() CIDR {
+	p, err := c.Parse()
+	if err != nil {
+		return c
+	}
+	return CIDR(p.Masked().String())
+}
+
+// Describe returns the network prefix field descriptor.
+func (c CIDR) Describe() Descriptor { return c.DescribeTag("") }
+
+// DescribeTag returns the network prefix field descriptor with the address
+// family restricted by the struct field tag.
+func (c CIDR) DescribeTag(tag reflect.StructTag) Descriptor {
+	family := tag.Get("ip")
+	return Descriptor{
+		Kind:        KindCIDR,
+		Placeholder: ipPlaceholder(family, "10.0.0.0/8", "2001:db8::/32"),
+		Hint:        true,
+		Validate: func(s string) error {
+			return c.SetValue(s).validate(family)
+		},
+	}
+}
+
+// MarshalText encodes the network prefix in canonical form.
+func (c CIDR) MarshalText() ([]byte, error) {
+	return []byte(c.Canonical()), nil
+}
+
+// Validate checks the network prefix. The empty prefix is valid.
+func (c CIDR) Validate() error { return c.validate("") }
+
+// ValidateTag checks the network prefix and its family set in the struct
+// field tag.
+func (c CIDR) ValidateTag(tag reflect.StructTag) error {
+	return c.validate(tag.Get("ip"))
+}
+
+// validate checks the network prefix and its family.
+func (c CIDR) validate(family string) error {
+	if c == "" {
+		return nil
+	}
+	p, err := c.Parse()
+	if err != nil {
+		return err
+	}
+	return checkFamily(p.Addr(), family)
+}
+
+// HostPort type. It is a host name or an IP address and a port, e.g.
+// "example.com:80", "[::1]:443" or ":8080".
+type HostPort string
+
+// GetValue returns the host and port.
+func (h HostPort) GetValue() string { return string(h) }
+
+// SetValue sets the host and port in canonical form.
+func (h HostPort) SetValue(val string) HostPort {
+	return HostPort(strings.TrimSpace(val)).Canonical()
+}
+
+// Split splits the host and port.
+func (h HostPort) Split() (host string, port Port, err error) {
+	host, p, err := net.SplitHostPort(string(h))
+	if err != nil {
+		return
+	}
+	port, err = parsePort(p)
+	return
+}
+
+// Canonical returns the host and port in canonical form with lower case host
+// name or canonical IP address. Invalid value is returned as is.
+func (h HostPort) Canonical() HostPort {
+	host, port, err := h.Split()
+	if err != nil {
+		return h
+	}
+	if addr, err := netip.ParseAddr(host); err == nil {
+		host = addr.String()
+	}
+	return HostPort(net.JoinHostPort(strings.ToLower(host), port.GetValue()))
+}
+
+// Describe returns the host and port field descriptor.
+func (h HostPort) Describe() Descriptor {
+	return Descriptor{
+		Kind:        KindHostPort,
+		Placeholder: "example.com:80",
+		Hint:        true,
+		Validate:    func(s string) error { return h.SetValue(s).Validate() },
+	}
+}
+
+// MarshalText encodes the host and port in canonical form.
+func (h HostPort) MarshalText() ([]byte, error) {
+	return []byte(h.Canonical()), nil
+}
+
+// Validate checks the host and port. The empty value is valid.
+func (h HostPort) Validate() error {
+	if h == "" {
+		return nil
+	}
+	_, port, err := h.Split()
+	if err != nil {
+		return err
+	}
+	return port.Validate()
+}
+
+// Port type. It is a TCP or UDP port number from 1 to 65535, zero is unset
+// port. The port range may be restricted with the "min" and "max" struct field
+// tags:
+//
+//	Port types.Port `json:"port" min:"1024"`
+type Port int
+
+// GetValue returns the port number as string.
+func (p Port) GetValue() string { return strconv.Itoa(int(p)) }
+
+// SetValue sets the port number from string. Invalid port number does not
+// change the port, use ParsePort to get the error.
+func (p Port) SetValue(val string) Port {
+	port, err := ParsePort(val)
+	if err != nil {
+		return p
+	}
+	return port
+}
+
+// Describe returns the port field descriptor.
+func (p Port) Describe() Descriptor { return p.DescribeTag("") }
+
+// DescribeTag returns the port field descriptor with the port range
+// restricted by the struct field tag.
+func (p Port) DescribeTag(tag reflect.StructTag) Descriptor {
+	min, max := portRange(tag)
+	return Descriptor{
+		Kind:        KindPort,
+		Placeholder: "8080",
+		Hint:        true,
+		Validate: func(s string) error {
+			port, err := ParsePort(s)
+			if err != nil {
+				return err
+			}
+			return port.validate(min, max)
+		},
+	}
+}
+
+// Validate checks the port number is from 0 to 65535.
+func (p Port) Validate() error {
+	if p < 0 || p > 65535 {
+		return fmt.Errorf("invalid port %d", p)
+	}
+	return nil
+}
+
+// ValidateTag checks the port number is within the port range set in the
+// struct field tag. The zero port is unset and valid.
+func (p Port) ValidateTag(tag reflect.StructTag) error {
+	if err := p.Validate(); err != nil {
+		return err
+	}
+	return p.validate(portRange(tag))
+}
+
+// validate checks the port number is from min to max. The zero port is unset
+// and valid.
+func (p Port) validate(min, max int) error {
+	if p != 0 && (int(p) < min || int(p) > max) {
+		return fmt.Errorf("port should be from %d to %d", min, max)
+	}
+	return nil
+}
+
+// ParsePort parses the port number from 0 to 65535.
+func ParsePort(s string) (Port, error) { return parsePort(strings.TrimSpace(s)) }
+
+// portRange returns the port range set in the struct field tag, from 1 to
+// 65535 by default.
+func portRange(tag reflect.StructTag) (min, max int) {
+	min, max = 1, 65535
+	if n, err := strconv.Atoi(tag.Get("min")); err == nil {
+		min = n
+	}
+	if n, err := strconv.Atoi(tag.Get("max")); err == nil {
+		max = n
+	}
+	return
+}
+
+// parsePort parses the port number from 0 to 65535.
+func parsePort(s string) (Port, error) {
+	n, err := strconv.Atoi(s)
+	if err != nil || n < 0 || n > 65535 {
+		return 0, fmt.Errorf("invalid port %q", s)
+	}
+	return Port(n), nil
+}
+
+// checkFamily checks the IP address family, "v4", "v6" or any if empty.
+func checkFamily(addr netip.Addr, family string) error {
+	switch {
+	case family == "v4" && !addr.Unmap().Is4():
+		return errors.New("IPv4 address expected")
+	case family == "v6" && (!addr.Is6() || addr.Is4In6()):
+		return errors.New("IPv6 address expected")
+	}
+	return nil
+}
+
+// ipPlaceholder returns the placeholder of the IP address family.
+func ipPlaceholder(family, v4, v6 string) string {
+	if family == "v6" {
+		return v6
+	}
+	return v4
+}
+
+// tagList returns comma separated values of the struct field tag.
+func tagList(tag reflect.StructTag, key string) (list []string) {
+	for _, s := range strings.Split(tag.Get(key), ",") {
+		if s = strings.TrimSpace(s); s != "" {
+			list = append(list, s)
+		}
+	}
+	return
+}
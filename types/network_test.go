@@ -0,0 +1,175 @@
+package types
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/teonet-go/conf"
+)
+
+func TestCanonical(t *testing.T) {
+
+	for _, test := range []struct {
+		value interface{ GetValue() string }
+		want  string
+	}{
+		{URL("").SetValue(" HTTPS://Example.COM/Path "), "https://example.com/Path"},
+		{URL("").SetValue("example.com"), "example.com"},
+		{IP("").SetValue("2001:DB8:0:0::1"), "2001:db8::1"},
+		{IP("").SetValue("::ffff:10.0.0.1"), "::ffff:10.0.0.1"},
+		{CIDR("").SetValue("10.1.2.3/8"), "10.0.0.0/8"},
+		{CIDR("").SetValue("2001:DB8::1/32"), "2001:db8::/32"},
+		{HostPort("").SetValue("Example.COM:80"), "example.com:80"},
+		{HostPort("").SetValue("[2001:DB8::1]:443"), "[2001:db8::1]:443"},
+		{HostPort("").SetValue(":8080"), ":8080"},
+		{HostPort("").SetValue("example.com"), "example.com"},
+		{Port(80).SetValue("http"), "80"},
+	} {
+		if got := test.value.GetValue(); got != test.want {
+			t.Fatalf("got %q, want %q", got, test.want)
+		}
+	}
+
+	// Saved values are canonical
+	data, err := json.Marshal(struct {
+		URL  URL
+		IP   IP
+		CIDR CIDR
+		Host HostPort
+	}{"HTTP://A.COM", "::FFFF", "10.0.0.1/24", "A.COM:1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `{"URL":"http://a.com","IP":"::ffff","CIDR":"10.0.0.0/24",` +
+		`"Host":"a.com:1"}`
+	if string(data) != want {
+		t.Fatalf("got %s, want %s", data, want)
+	}
+}
+
+func TestNetworkValidate(t *testing.T) {
+
+	for _, test := range []struct {
+		value interface{ Validate() error }
+		valid bool
+	}{
+		{URL(""), true},
+		{URL("https://example.com"), true},
+		{URL("mailto:user@example.com"), true},
+		{URL("example.com"), false},
+		{IP("10.0.0.1"), true},
+		{IP("2001:db8::1"), true},
+		{IP("10.0.0.256"), false},
+		{CIDR("10.0.0.0/8"), true},
+		{CIDR("10.0.0.0"), false},
+		{CIDR("10.0.0.0/33"), false},
+		{HostPort("example.com:80"), true},
+		{HostPort(":0"), true},
+		{HostPort("example.com"), false},
+		{HostPort("example.com:http"), false},
+		{HostPort("example.com:70000"), false},
+		{Port(0), true},
+		{Port(65535), true},
+		{Port(65536), false},
+		{Port(-1), false},
+	} {
+		if err := test.value.Validate(); (err == nil) != test.valid {
+			t.Fatalf("%T %v: got error %v, want valid %v", test.value,
+				test.value, err, test.valid)
+		}
+	}
+
+	// Schemes, address family and port range are taken from the tags
+	type config struct {
+		Server  URL  `schemes:"https"`
+		Listen  IP   `ip:"v4"`
+		Network CIDR `ip:"v6"`
+		Port    Port `min:"1024" max:"9000"`
+	}
+	valid := config{"https://example.com", "10.0.0.1", "2001:db8::/32", 8080}
+	if err := conf.Validate(valid); err != nil {
+		t.Fatal(err)
+	}
+	for name, change := range map[string]func(c *config){
+		"url scheme":   func(c *config) { c.Server = "http://example.com" },
+		"relative url": func(c *config) { c.Server = "/path" },
+		"ip family":    func(c *config) { c.Listen = "::1" },
+		"invalid ip":   func(c *config) { c.Listen = "10.0.0" },
+		"cidr family":  func(c *config) { c.Network = "10.0.0.0/8" },
+		"port min":     func(c *config) { c.Port = 80 },
+		"port max":     func(c *config) { c.Port = 9090 },
+		"port number":  func(c *config) { c.Port = 70000 },
+	} {
+		c := valid
+		change(&c)
+		if conf.Validate(c) == nil {
+			t.Fatalf("%s: invalid value should be an error", name)
+		}
+	}
+}
+
+func TestPort(t *testing.T) {
+
+	for _, test := range []struct {
+		s    string
+		want Port
+		ok   bool
+	}{
+		{"80", 80, true},
+		{" 443 ", 443, true},
+		{"0", 0, true},
+		{"65536", 0, false},
+		{"-1", 0, false},
+		{"http", 0, false},
+	} {
+		port, err := ParsePort(test.s)
+		if (err == nil) != test.ok || port != test.want {
+			t.Fatalf("%q: got %v %v, want %v", test.s, port, err, test.want)
+		}
+
+		// Invalid port does not change the value
+		want := test.want
+		if !test.ok {
+			want = 8080
+		}
+		if port = Port(8080).SetValue(test.s); port != want {
+			t.Fatalf("%q: got port %v, want %v", test.s, port, want)
+		}
+	}
+
+	d := Port(0).DescribeTag(`min:"1024" max:"2048"`)
+	for s, ok := range map[string]bool{"1024": true, "2048": true, "0": true,
+		"80": false, "4096": false, "x": false} {
+		if err := d.Validate(s); (err == nil) != ok {
+			t.Fatalf("%s: got error %v, want valid %v", s, err, ok)
+		}
+	}
+}
+
+func TestSetFieldValue(t *testing.T) {
+
+	field := &conf.Field[any]{Value: Port(8080), Tag: `min:"1024"`}
+
+	// Invalid values are not set
+	for _, val := range []string{"http", "70000", "80"} {
+		ok, err := SetFieldValueE(field, val)
+		if !ok || err == nil {
+			t.Fatalf("%s: invalid port should be an error", val)
+		}
+		if field.Value != Port(8080) {
+			t.Fatalf("%s: invalid port should not be set", val)
+		}
+	}
+	if ok, err := SetFieldValueE(field, "8443"); !ok || err != nil {
+		t.Fatal(ok, err)
+	}
+	if val, _ := GetFieldValue(field); val != "8443" {
+		t.Fatalf("got port %s, want 8443", val)
+	}
+
+	// Not registered field type
+	field = &conf.Field[any]{Value: 1}
+	if ok, _ := SetFieldValueE(field, "2"); ok {
+		t.Fatal("int should not be registered")
+	}
+}
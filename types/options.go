@@ -7,11 +7,8 @@
 package types
 
 import (
+	"fmt"
 	"slices"
-
-	"fyne.io/fyne/v2"
-	"fyne.io/fyne/v2/widget"
-	"github.com/teonet-go/conf"
 )
 
 // RadioGroup type.
@@ -66,17 +63,22 @@ func (o *RadioGroup) SetVertical() {
 	o.Horizontal = false
 }
 
-// GetWidgetValue returns the widget value.
-func (p RadioGroup) GetWidgetValue(field *conf.Field[fyne.CanvasObject]) string {
-	return field.Entry.(*widget.RadioGroup).Selected
+// Describe returns the radio group field descriptor.
+func (o RadioGroup) Describe() Descriptor {
+	return Descriptor{
+		Kind:       KindRadio,
+		Options:    o.GetOptions(),
+		Horizontal: o.GetHorizontal(),
+		Validate:   func(s string) error { return o.SetValue(s).Validate() },
+	}
 }
 
-// NewWidget creates and returns widget and true if hint for this field is
-// supported.
-func (o RadioGroup) NewWidget() (fyne.CanvasObject, bool) {
-	opts, h := o.GetParams()
-	w := widget.NewRadioGroup(opts, func(s string) {})
-	w.Selected = GetValue(o)
-	w.Horizontal = h
-	return w, false
+// Validate checks the selected option index is within options. The radio
+// group without options is valid.
+func (o RadioGroup) Validate() error {
+	if len(o.Options) > 0 && (o.Selected < 0 || o.Selected >= len(o.Options)) {
+		return fmt.Errorf("selected option %d is out of %d options",
+			o.Selected, len(o.Options))
+	}
+	return nil
 }
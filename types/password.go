@@ -7,11 +7,65 @@
 package types
 
 import (
-	"fyne.io/fyne/v2"
-	"fyne.io/fyne/v2/widget"
-	"github.com/teonet-go/conf"
+	"errors"
+	"fmt"
+	"strings"
+	"unicode"
 )
 
+// PasswordPolicy contains password requirements. The zero policy accepts any
+// password.
+type PasswordPolicy struct {
+	MinLength    int  // Minimum number of characters
+	RequireUpper bool // Require upper case letter
+	RequireLower bool // Require lower case letter
+	RequireDigit bool // Require digit
+	RequireOther bool // Require character which is not a letter or a digit
+}
+
+// DefaultPasswordPolicy is the policy used by Password.Validate. The
+// application may change it at startup.
+var DefaultPasswordPolicy PasswordPolicy
+
+// Check checks the password meets the policy requirements.
+func (p PasswordPolicy) Check(password string) error {
+	var upper, lower, digit, other bool
+	for _, r := range password {
+		switch {
+		case unicode.IsUpper(r):
+			upper = true
+		case unicode.IsLower(r):
+			lower = true
+		case unicode.IsDigit(r):
+			digit = true
+		default:
+			other = true
+		}
+	}
+
+	var errs []string
+	if n := len([]rune(password)); n < p.MinLength {
+		errs = append(errs, fmt.Sprintf("at least %d characters", p.MinLength))
+	}
+	for _, req := range []struct {
+		required, ok bool
+		text         string
+	}{
+		{p.RequireUpper, upper, "an upper case letter"},
+		{p.RequireLower, lower, "a lower case letter"},
+		{p.RequireDigit, digit, "a digit"},
+		{p.RequireOther, other, "a special character"},
+	} {
+		if req.required && !req.ok {
+			errs = append(errs, req.text)
+		}
+	}
+	if len(errs) > 0 {
+		return errors.New("password should contain " + strings.Join(errs, ", "))
+	}
+	return nil
+}
+
 // Password type.
 type Password string
 
@@ -25,15 +79,21 @@ func (p Password) SetValue(val string) Password {
 	return Password(val)
 }
 
-// GetWidgetValue returns the widget value.
-func (p Password) GetWidgetValue(field *conf.Field[fyne.CanvasObject]) string {
-	return field.Entry.(*widget.Entry).Text
+// IsSecret returns true, password values are redacted in conf logs.
+func (p Password) IsSecret() bool {
+	return true
+}
+
+// Describe returns the password field descriptor.
+func (p Password) Describe() Descriptor {
+	return Descriptor{
+		Kind:     KindPassword,
+		Secret:   true,
+		Validate: func(s string) error { return p.SetValue(s).Validate() },
+	}
 }
 
-// NewWidget creates and returns widget and true if hint for this field is
-// supported.
-func (p Password) NewWidget() (fyne.CanvasObject, bool) {
-	w := widget.NewPasswordEntry()
-	w.SetText(GetValue(p))
-	return w, false
+// Validate checks the password meets DefaultPasswordPolicy.
+func (p Password) Validate() error {
+	return DefaultPasswordPolicy.Check(string(p))
 }
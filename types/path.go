@@ -0,0 +1,242 @@
+// Copyright 2024 Kirill Scherba <kirill@scherba.ru>. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// File and directory path entries.
+
+package types
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strings"
+)
+
+// FilePath type. It is a path to a file. Path checks are set with the "path"
+// struct field tag options: "exists" requires the file to exist, "readable"
+// and "writable" require file permissions, "relative" resolves relative path
+// against the config file directory passed to DescribeDir and ValidateDir,
+// e.g. by conf.File.Validate. Allowed file extensions are set with the "ext"
+// tag:
+//
+//	Cert types.FilePath `json:"cert" path:"exists,readable,relative" ext:".pem,.crt"`
+type FilePath string
+
+// DirPath type. It is a path to a directory with the same "path" struct field
+// tag options as FilePath.
+type DirPath string
+
+// GetValue returns the file path.
+func (p FilePath) GetValue() string { return string(p) }
+
+// SetValue sets the file path.
+func (p FilePath) SetValue(val string) FilePath {
+	return FilePath(strings.TrimSpace(val))
+}
+
+// Resolve returns the path resolved against the base directory if it is
+// relative.
+func (p FilePath) Resolve(base string) string {
+	return resolvePath(string(p), base)
+}
+
+// Describe returns the file path field descriptor.
+func (p FilePath) Describe() Descriptor { return p.DescribeDir("", "") }
+
+// DescribeTag returns the file path field descriptor with path checks and
+// extensions from the struct field tag.
+func (p FilePath) DescribeTag(tag reflect.StructTag) Descriptor {
+	return p.DescribeDir(tag, "")
+}
+
+// DescribeDir returns the file path field descriptor with path checks and
+// extensions from the struct field tag. Relative paths are resolved against
+// the config file directory dir, the empty dir is the current directory.
+func (p FilePath) DescribeDir(tag reflect.StructTag, dir string) Descriptor {
+	opts := newPathOptions(tag, false, dir)
+	return Descriptor{
+		Kind:        KindFilePath,
+		Placeholder: "/path/to/file",
+		Extensions:  opts.exts,
+		BaseDir:     opts.base,
+		Validate:    func(s string) error { return opts.check(s) },
+	}
+}
+
+// Validate checks the existing path is not a directory. The empty path is
+// valid.
+func (p FilePath) Validate() error { return p.ValidateDir("", "") }
+
+// ValidateTag checks the path with path checks and extensions from the struct
+// field tag.
+func (p FilePath) ValidateTag(tag reflect.StructTag) error {
+	return p.ValidateDir(tag, "")
+}
+
+// ValidateDir checks the path with path checks and extensions from the struct
+// field tag. Relative paths are resolved against the config file directory
+// dir.
+func (p FilePath) ValidateDir(tag reflect.StructTag, dir string) error {
+	return newPathOptions(tag, false, dir).check(string(p))
+}
+
+// GetValue returns the directory path.
+func (p DirPath) GetValue() string { return string(p) }
+
+// SetValue sets the directory path.
+func (p DirPath) SetValue(val string) DirPath {
+	return DirPath(strings.TrimSpace(val))
+}
+
+// Resolve returns the path resolved against the base directory if it is
+// relative.
+func (p DirPath) Resolve(base string) string {
+	return resolvePath(string(p), base)
+}
+
+// Describe returns the directory path field descriptor.
+func (p DirPath) Describe() Descriptor { return p.DescribeDir("", "") }
+
+// DescribeTag returns the directory path field descriptor with path checks
+// from the struct field tag.
+func (p DirPath) DescribeTag(tag reflect.StructTag) Descriptor {
+	return p.DescribeDir(tag, "")
+}
+
+// DescribeDir returns the directory path field descriptor with path checks
+// from the struct field tag. Relative paths are resolved against the config
+// file directory dir, the empty dir is the current directory.
+func (p DirPath) DescribeDir(tag reflect.StructTag, dir string) Descriptor {
+	opts := newPathOptions(tag, true, dir)
+	return Descriptor{
+		Kind:        KindDirPath,
+		Placeholder: "/path/to/directory",
+		BaseDir:     opts.base,
+		Validate:    func(s string) error { return opts.check(s) },
+	}
+}
+
+// Validate checks the existing path is a directory. The empty path is valid.
+func (p DirPath) Validate() error { return p.ValidateDir("", "") }
+
+// ValidateTag checks the path with path checks from the struct field tag.
+func (p DirPath) ValidateTag(tag reflect.StructTag) error {
+	return p.ValidateDir(tag, "")
+}
+
+// ValidateDir checks the path with path checks from the struct field tag.
+// Relative paths are resolved against the config file directory dir.
+func (p DirPath) ValidateDir(tag reflect.StructTag, dir string) error {
+	return newPathOptions(tag, true, dir).check(string(p))
+}
+
+// pathOptions contains path checks from the struct field tag.
+type pathOptions struct {
+	dir      bool     // Path is a directory
+	exists   bool     // Path should exist
+	readable bool     // Path should be readable
+	writable bool     // Path should be writable
+	base     string   // Base directory of relative path or empty
+	exts     []string // Allowed file extensions
+}
+
+// newPathOptions returns path checks from the struct field tag. Relative paths
+// with the "relative" option are resolved against the base directory.
+func newPathOptions(tag reflect.StructTag, dir bool, base string) (
+	o pathOptions) {
+
+	o.dir = dir
+	for _, opt := range tagList(tag, "path") {
+		switch opt {
+		case "exists":
+			o.exists = true
+		case "readable":
+			o.readable = true
+		case "writable":
+			o.writable = true
+		case "relative":
+			o.base = base
+			if o.base == "" {
+				o.base = "."
+			}
+		}
+	}
+	if !dir {
+		o.exts = tagList(tag, "ext")
+	}
+	return
+}
+
+// check checks the path. The empty path is valid unless it should exist.
+func (o pathOptions) check(path string) error {
+	if path == "" {
+		if o.exists {
+			return errors.New("path is required")
+		}
+		return nil
+	}
+	if len(o.exts) > 0 && !slices.Contains(o.exts, filepath.Ext(path)) {
+		return fmt.Errorf("file extension should be one of %s",
+			strings.Join(o.exts, ", "))
+	}
+	path = resolvePath(path, o.base)
+
+	fi, err := os.Stat(path)
+	switch {
+	case errors.Is(err, fs.ErrNotExist) && !o.exists:
+		return nil
+	case err != nil:
+		return err
+	case o.dir && !fi.IsDir():
+		return fmt.Errorf("%s is not a directory", path)
+	case !o.dir && fi.IsDir():
+		return fmt.Errorf("%s is a directory", path)
+	}
+	if o.readable {
+		if err = checkAccess(path, o.dir, os.O_RDONLY); err != nil {
+			return err
+		}
+	}
+	if o.writable {
+		if err = checkAccess(path, o.dir, os.O_WRONLY); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// checkAccess checks the path can be opened with the flag. Directories are
+// checked by reading or by creating a temporary file.
+func checkAccess(path string, dir bool, flag int) error {
+	if !dir {
+		f, err := os.OpenFile(path, flag, 0)
+		if err != nil {
+			return err
+		}
+		return f.Close()
+	}
+	if flag == os.O_RDONLY {
+		_, err := os.ReadDir(path)
+		return err
+	}
+	f, err := os.CreateTemp(path, ".access-*")
+	if err != nil {
+		return err
+	}
+	f.Close()
+	return os.Remove(f.Name())
+}
+
+// resolvePath returns the path joined with the base directory if the path is
+// relative and the base is not empty.
+func resolvePath(path, base string) string {
+	if base == "" || path == "" || filepath.IsAbs(path) {
+		return path
+	}
+	return filepath.Join(base, path)
+}
@@ -0,0 +1,69 @@
+package types
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/teonet-go/conf"
+)
+
+func TestPathValidate(t *testing.T) {
+
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "cert.pem"), nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, test := range []struct {
+		path  FilePath
+		tag   reflect.StructTag
+		dir   string
+		valid bool
+	}{
+		{"", ``, "", true},
+		{"", `path:"exists"`, "", false},
+		{"missing.pem", ``, "", true},
+		{"missing.pem", `path:"exists,relative"`, dir, false},
+		{"cert.pem", `path:"exists,relative"`, dir, true},
+		{"cert.pem", `path:"exists"`, dir, false},
+		{FilePath(filepath.Join(dir, "cert.pem")), `path:"exists"`, "", true},
+		{"cert.pem", `path:"relative" ext:".pem,.crt"`, dir, true},
+		{"cert.key", `path:"relative" ext:".pem,.crt"`, dir, false},
+		{FilePath(dir), ``, "", false},
+	} {
+		err := test.path.ValidateDir(test.tag, test.dir)
+		if (err == nil) != test.valid {
+			t.Fatalf("%s %s: got error %v, want valid %v", test.path,
+				test.tag, err, test.valid)
+		}
+	}
+
+	for path, valid := range map[DirPath]bool{
+		"":                            true,
+		DirPath(dir):                  true,
+		DirPath(dir + "/cert.pem"):    false,
+		DirPath(dir + "/missing/dir"): true,
+	} {
+		if err := path.Validate(); (err == nil) != valid {
+			t.Fatalf("%s: got error %v, want valid %v", path, err, valid)
+		}
+	}
+
+	// Relative paths are resolved against the config file directory
+	type config struct {
+		Cert FilePath `path:"exists,relative"`
+	}
+	f := conf.NewFile(filepath.Join(dir, "config.json"))
+	if err := f.Validate(config{"cert.pem"}); err != nil {
+		t.Fatal(err)
+	}
+	if f.Validate(config{"missing.pem"}) == nil {
+		t.Fatal("missing file should be an error")
+	}
+	field := &conf.Field[any]{Value: FilePath(""), Tag: `path:"relative"`}
+	if d, _ := DescribeFieldDir(field, dir); d.BaseDir != dir {
+		t.Fatalf("got base dir %q, want %q", d.BaseDir, dir)
+	}
+}
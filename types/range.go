@@ -0,0 +1,176 @@
+// Copyright 2024 Kirill Scherba <kirill@scherba.ru>. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Numeric range slider.
+
+package types
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+
+	"github.com/teonet-go/conf"
+)
+
+// Range type. It is a number within bounds changed by step, stored in the
+// config file as the number. The bounds and the step are not stored, they are
+// set in the default value or taken from the "min", "max" and "step" struct
+// field tags:
+//
+//	Volume types.Range[int] `json:"volume" min:"0" max:"100" step:"5"`
+//
+// Ranges of all built-in number types are registered, ranges of other number
+// types should be registered with Register.
+type Range[T conf.Number] struct {
+	Min   T // Minimum value
+	Max   T // Maximum value, bounds are not checked if it is not above Min
+	Step  T // Value step from Min, zero is any step
+	Value T // Current value
+}
+
+// NewRange creates and returns new range with value.
+func NewRange[T conf.Number](min, max, step, value T) Range[T] {
+	return Range[T]{Min: min, Max: max, Step: step, Value: value}
+}
+
+// GetValue returns the range value as string.
+func (r Range[T]) GetValue() string { return conf.NumberToString(r.Value) }
+
+// SetValue sets the range value from string. Invalid number or number out of
+// T range does not change the value.
+func (r Range[T]) SetValue(val string) Range[T] {
+	if v, err := parseRange[T](val); err == nil {
+		r.Value = v
+	}
+	return r
+}
+
+// SetBounds sets the range bounds and step.
+func (r *Range[T]) SetBounds(min, max, step T) {
+	r.Min, r.Max, r.Step = min, max, step
+}
+
+// MarshalJSON encodes the range value as JSON number.
+func (r Range[T]) MarshalJSON() ([]byte, error) {
+	return json.Marshal(r.Value)
+}
+
+// UnmarshalJSON decodes the range value from JSON number. The bounds and the
+// step are not changed.
+func (r *Range[T]) UnmarshalJSON(data []byte) error {
+	return json.Unmarshal(data, &r.Value)
+}
+
+// Describe returns the range field descriptor.
+func (r Range[T]) Describe() Descriptor { return r.DescribeTag("") }
+
+// DescribeTag returns the range field descriptor. The bounds and the step are
+// taken from the struct field tag if set. The step of integer ranges is at
+// least one.
+func (r Range[T]) DescribeTag(tag reflect.StructTag) Descriptor {
+	r = r.withTag(tag)
+	step := float64(r.Step)
+	if step == 0 && isInteger[T]() {
+		step = 1
+	}
+	return Descriptor{
+		Kind: KindRange,
+		Min:  float64(r.Min),
+		Max:  float64(r.Max),
+		Step: step,
+		Validate: func(s string) error {
+			v, err := parseRange[T](s)
+			if err != nil {
+				return fmt.Errorf("invalid number %q", s)
+			}
+			r.Value = v
+			return r.Validate()
+		},
+	}
+}
+
+// Validate checks the value is within bounds and is a multiple of step from
+// Min.
+func (r Range[T]) Validate() error {
+	if r.Min >= r.Max {
+		return nil
+	}
+	if r.Value < r.Min || r.Value > r.Max {
+		return fmt.Errorf("value should be from %v to %v", r.Min, r.Max)
+	}
+	if r.Step > 0 {
+		steps := (float64(r.Value) - float64(r.Min)) / float64(r.Step)
+		if math.Abs(steps-math.Round(steps)) > 1e-9 {
+			return fmt.Errorf("value should be %v plus a multiple of %v",
+				r.Min, r.Step)
+		}
+	}
+	return nil
+}
+
+// ValidateTag checks the value like Validate with the bounds and the step
+// taken from the struct field tag if set.
+func (r Range[T]) ValidateTag(tag reflect.StructTag) error {
+	return r.withTag(tag).Validate()
+}
+
+// withTag returns the range with the bounds and the step taken from the
+// struct field tag if set.
+func (r Range[T]) withTag(tag reflect.StructTag) Range[T] {
+	for _, limit := range []struct {
+		name string
+		v    *T
+	}{{"min", &r.Min}, {"max", &r.Max}, {"step", &r.Step}} {
+		if s := tag.Get(limit.name); s != "" {
+			if n, err := parseRange[T](s); err == nil {
+				*limit.v = n
+			}
+		}
+	}
+	return r
+}
+
+// parseRange parses the range number of type T from string. Integers are
+// parsed at the bit size of T, numbers out of T range, NaN and infinities are
+// invalid.
+func parseRange[T conf.Number](s string) (n T, err error) {
+	v := reflect.ValueOf(&n).Elem()
+	bits := v.Type().Bits()
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32,
+		reflect.Int64:
+		var i int64
+		if i, err = strconv.ParseInt(s, 10, bits); err == nil {
+			v.SetInt(i)
+		}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32,
+		reflect.Uint64, reflect.Uintptr:
+		var u uint64
+		if u, err = strconv.ParseUint(s, 10, bits); err == nil {
+			v.SetUint(u)
+		}
+	default:
+		var f float64
+		f, err = strconv.ParseFloat(s, bits)
+		if err == nil && (math.IsNaN(f) || math.IsInf(f, 0)) {
+			err = fmt.Errorf("invalid number %q", s)
+		}
+		if err == nil {
+			v.SetFloat(f)
+		}
+	}
+	if err != nil {
+		return 0, err
+	}
+	return
+}
+
+// isInteger returns true if T is an integer type.
+func isInteger[T conf.Number]() bool {
+	half := 0.5
+	return T(half) == 0
+}
@@ -0,0 +1,114 @@
+package types
+
+import (
+	"encoding/json"
+	"math"
+	"reflect"
+	"testing"
+
+	"github.com/teonet-go/conf"
+)
+
+func TestRangeValue(t *testing.T) {
+
+	// Ranges of all built-in number types are registered
+	for _, v := range []any{
+		Range[int]{}, Range[int8]{}, Range[int16]{}, Range[int32]{},
+		Range[int64]{}, Range[uint]{}, Range[uint8]{}, Range[uint16]{},
+		Range[uint32]{}, Range[uint64]{}, Range[uintptr]{}, Range[float32]{},
+		Range[float64]{},
+	} {
+		if !Registered(reflect.TypeOf(v)) {
+			t.Fatalf("%T should be registered", v)
+		}
+	}
+
+	// Bounds and step are taken from the tag
+	type config struct {
+		Volume Range[int]     `min:"0" max:"100" step:"5"`
+		Ratio  Range[float64] `min:"0" max:"1"`
+	}
+	valid := config{Range[int]{Value: 50}, Range[float64]{Value: 0.5}}
+	if err := conf.Validate(valid); err != nil {
+		t.Fatal(err)
+	}
+	for name, change := range map[string]func(c *config){
+		"range bounds":       func(c *config) { c.Volume.Value = 500 },
+		"range step":         func(c *config) { c.Volume.Value = 52 },
+		"float range bounds": func(c *config) { c.Ratio.Value = 1.5 },
+	} {
+		c := valid
+		change(&c)
+		if conf.Validate(c) == nil {
+			t.Fatalf("%s: invalid value should be an error", name)
+		}
+	}
+	field := &conf.Field[any]{Value: Range[int]{Value: 10}, Tag: `max:"100"`}
+	d, _ := DescribeField(field)
+	if d.Max != 100 || d.Step != 1 {
+		t.Fatalf("wrong descriptor %+v", d)
+	}
+	if d.Validate("500") == nil {
+		t.Fatal("value above max should be an error")
+	}
+	if r := (Range[int]{Value: 10}).SetValue("x"); r.Value != 10 {
+		t.Fatal("invalid number should not change the value")
+	}
+
+	// Numbers out of the type range are invalid
+	if r := (Range[uint8]{Value: 10}).SetValue("300"); r.Value != 10 {
+		t.Fatalf("overflow should not change the value, got %d", r.Value)
+	}
+	if r := (Range[int8]{Value: 10}).SetValue("-129"); r.Value != 10 {
+		t.Fatalf("overflow should not change the value, got %d", r.Value)
+	}
+	if r := (Range[float32]{Value: 1}).SetValue("1e39"); r.Value != 1 {
+		t.Fatalf("overflow should not change the value, got %v", r.Value)
+	}
+	if r := (Range[float64]{Value: 1}).SetValue("NaN"); r.Value != 1 {
+		t.Fatalf("NaN should not change the value, got %v", r.Value)
+	}
+	if r := (Range[uint64]{}).SetValue("18446744073709551615"); r.Value !=
+		math.MaxUint64 {
+		t.Fatalf("got %d, want max uint64", r.Value)
+	}
+	r8 := Range[uint8]{Max: 250, Value: 255}
+	if r8.ValidateTag(`max:"256"`) == nil {
+		t.Fatal("out of range tag bound should not wrap to 0")
+	}
+	if d := r8.DescribeTag(`max:"256"`); d.Max != 250 || d.Validate("300") == nil {
+		t.Fatalf("wrong uint8 descriptor %+v", d)
+	}
+	r64 := Range[uint64]{Value: 1 << 63}
+	if r64.ValidateTag(`min:"9223372036854775808" max:"18446744073709551615"`) !=
+		nil {
+		t.Fatal("uint64 above max int64 should be valid")
+	}
+
+	// Fields and differences show the range value
+	type volume struct {
+		Volume Range[int]
+	}
+	a, b := volume{NewRange(0, 100, 1, 10)}, volume{NewRange(0, 100, 1, 20)}
+	fields := conf.GetFields(a, func(*conf.Field[any]) {})
+	if len(fields) != 1 || fields[0].ValueStr != "10" {
+		t.Fatalf("wrong fields %+v", fields)
+	}
+	diff := conf.Diff(a, b)
+	if len(diff) != 1 || diff[0].Path != "/Volume" || diff[0].Old != "10" ||
+		diff[0].New != "20" {
+		t.Fatalf("wrong differences %+v", diff)
+	}
+
+	// Only the value is saved
+	a.Volume = NewRange(0, 10, 1, 5)
+	if err := json.Unmarshal([]byte(`{"Volume":30}`), &a); err != nil {
+		t.Fatal(err)
+	}
+	if a.Volume.Max != 10 || a.Volume.Value != 30 {
+		t.Fatal("bounds should not be changed by unmarshal")
+	}
+	if data, _ := json.Marshal(a); string(data) != `{"Volume":30}` {
+		t.Fatalf("got %s, want {\"Volume\":30}", data)
+	}
+}
@@ -0,0 +1,111 @@
+// Copyright 2024 Kirill Scherba <kirill@scherba.ru>. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Special field types registry.
+
+package types
+
+import (
+	"reflect"
+	"sync"
+)
+
+// registered describes the registered special field type.
+type registered struct {
+	describe func(value any, tag reflect.StructTag, dir string) Descriptor
+	getValue func(value any) string
+	setValue func(value any, val string) any
+}
+
+// registry contains registered special field types by reflect.Type.
+var registry struct {
+	sync.RWMutex
+	types map[reflect.Type]registered
+}
+
+// Register the special field types of this package.
+func init() {
+	Register[Email]()
+	Register[Password]()
+	Register[Multiline]()
+	Register[RadioGroup]()
+	Register[Select]()
+	Register[SelectEntry]()
+	Register[CheckGroup]()
+	Register[URL]()
+	Register[IP]()
+	Register[CIDR]()
+	Register[HostPort]()
+	Register[Port]()
+	Register[FilePath]()
+	Register[DirPath]()
+	Register[Color]()
+	Register[Range[int]]()
+	Register[Range[int8]]()
+	Register[Range[int16]]()
+	Register[Range[int32]]()
+	Register[Range[int64]]()
+	Register[Range[uint]]()
+	Register[Range[uint8]]()
+	Register[Range[uint16]]()
+	Register[Range[uint32]]()
+	Register[Range[uint64]]()
+	Register[Range[uintptr]]()
+	Register[Range[float32]]()
+	Register[Range[float64]]()
+	Register[KeyValue]()
+}
+
+// Register registers the special field type T. Renderers describe fields of
+// type T with T.Describe, with T.DescribeTag if T implements TagDescriber or
+// with T.DescribeDir if T implements DirDescriber, and set edited values with
+// T.SetValue. Application packages use Register to
+// add their own field types. Registering the type again replaces it.
+func Register[T Types[T]]() {
+	register(reflect.TypeOf((*T)(nil)).Elem(), registered{
+		describe: func(value any, tag reflect.StructTag, dir string) Descriptor {
+			if d, ok := value.(DirDescriber); ok {
+				return d.DescribeDir(tag, dir)
+			}
+			if d, ok := value.(TagDescriber); ok {
+				return d.DescribeTag(tag)
+			}
+			return value.(T).Describe()
+		},
+		getValue: func(value any) string { return value.(T).GetValue() },
+		setValue: func(value any, val string) any {
+			return value.(T).SetValue(val)
+		},
+	})
+}
+
+// register adds the special field type to the registry.
+func register(t reflect.Type, r registered) {
+	registry.Lock()
+	defer registry.Unlock()
+
+	if registry.types == nil {
+		registry.types = make(map[reflect.Type]registered)
+	}
+	registry.types[t] = r
+}
+
+// Registered returns true if the type t is registered special field type.
+func Registered(t reflect.Type) bool {
+	registry.RLock()
+	defer registry.RUnlock()
+	_, ok := registry.types[t]
+	return ok
+}
+
+// lookup returns the registered special field type of the value.
+func lookup(value any) (r registered, ok bool) {
+	if value == nil {
+		return
+	}
+	registry.RLock()
+	defer registry.RUnlock()
+	r, ok = registry.types[reflect.TypeOf(value)]
+	return
+}
@@ -0,0 +1,76 @@
+package types
+
+import (
+	"reflect"
+	"testing"
+
+	"fyne.io/fyne/v2"
+	"github.com/teonet-go/conf"
+)
+
+type registryLevel string
+
+func (l registryLevel) GetValue() string { return string(l) }
+
+func (l registryLevel) SetValue(val string) registryLevel {
+	return registryLevel(val)
+}
+
+func (l registryLevel) Describe() Descriptor {
+	return Descriptor{Kind: "level", Options: []string{"debug", "info"}}
+}
+
+func TestRegister(t *testing.T) {
+
+	level := reflect.TypeOf(registryLevel(""))
+	if Registered(level) {
+		t.Fatal("type should not be registered before Register")
+	}
+	Register[registryLevel]()
+
+	// Types are found by reflect.Type
+	for typ, want := range map[reflect.Type]bool{
+		level:                      true,
+		reflect.TypeOf(Email("")):  true,
+		reflect.TypeOf(""):         false,
+		reflect.TypeOf(new(Email)): false,
+		nil:                        false,
+	} {
+		if Registered(typ) != want {
+			t.Fatalf("%v: registered should be %v", typ, want)
+		}
+	}
+
+	// Registered values and fields are described by their type
+	if d, ok := Describe(registryLevel("info")); !ok || d.Kind != "level" {
+		t.Fatalf("wrong descriptor %+v", d)
+	}
+	if _, ok := Describe(nil); ok {
+		t.Fatal("nil should not be described")
+	}
+	field := &conf.Field[any]{Value: registryLevel("info")}
+	if val, ok := GetFieldValue(field); !ok || val != "info" {
+		t.Fatalf("got value %q, want info", val)
+	}
+	if !SetFieldValue(field, "debug") || field.Value != registryLevel("debug") {
+		t.Fatalf("got value %v, want debug", field.Value)
+	}
+
+	// Fields of not registered types are not changed
+	field.Value = "info"
+	if _, ok := GetFieldValue(field); ok {
+		t.Fatal("string field should not be registered")
+	}
+	if SetFieldValue(field, "debug") || field.Value != "info" {
+		t.Fatal("string field should not be set")
+	}
+
+	// Deprecated widget functions do nothing without fyne/form package
+	wfield := &conf.Field[fyne.CanvasObject]{Value: registryLevel("info")}
+	if _, _, ok := CheckWidget(wfield); ok || CheckSave(wfield) {
+		t.Fatal("widget should not be created without fyne/form")
+	}
+	if _, ok := NewWidget[registryLevel](wfield); ok {
+		t.Fatal("widget should not be created without fyne/form")
+	}
+}
@@ -0,0 +1,188 @@
+// Copyright 2024 Kirill Scherba <kirill@scherba.ru>. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Select options.
+
+package types
+
+import (
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/teonet-go/conf"
+)
+
+// Select type. Only the selected option is stored in the config file, the
+// options are taken from the "options" struct field tag:
+//
+//	Level types.Select `json:"level" options:"debug,info,error"`
+//
+// or from the options provider registered with RegisterOptions and referenced
+// in the tag by name with "@" prefix:
+//
+//	Iface types.Select `json:"iface" options:"@interfaces"`
+type Select string
+
+// SelectEntry type is like Select but allows to enter value which is not in
+// options.
+type SelectEntry string
+
+// TagDescriber is implemented by special field types which descriptors depend
+// on the struct field tag.
+type TagDescriber interface {
+	DescribeTag(tag reflect.StructTag) Descriptor
+}
+
+// DirDescriber is implemented by special field types which descriptors depend
+// on the struct field tag and the config file directory.
+type DirDescriber interface {
+	DescribeDir(tag reflect.StructTag, dir string) Descriptor
+}
+
+// GetValue returns the selected option.
+func (s Select) GetValue() string { return string(s) }
+
+// SetValue sets the selected option.
+func (s Select) SetValue(val string) Select { return Select(val) }
+
+// Describe returns the select field descriptor without options.
+func (s Select) Describe() Descriptor { return s.DescribeTag("") }
+
+// DescribeTag returns the select field descriptor with options from the
+// struct field tag. The value should be one of the options.
+func (s Select) DescribeTag(tag reflect.StructTag) Descriptor {
+	options := TagOptions(tag)
+	return Descriptor{
+		Kind:    KindSelect,
+		Options: options,
+		Validate: func(val string) error {
+			return checkOption(options, val)
+		},
+	}
+}
+
+// Validate checks the selected option. The select without options in the
+// struct field tag accepts any value.
+func (s Select) Validate() error { return s.ValidateTag("") }
+
+// ValidateTag checks the selected option is one of options from the struct
+// field tag.
+func (s Select) ValidateTag(tag reflect.StructTag) error {
+	return checkOption(TagOptions(tag), string(s))
+}
+
+// GetValue returns the selected or entered value.
+func (s SelectEntry) GetValue() string { return string(s) }
+
+// SetValue sets the selected or entered value.
+func (s SelectEntry) SetValue(val string) SelectEntry { return SelectEntry(val) }
+
+// Describe returns the select entry field descriptor without options.
+func (s SelectEntry) Describe() Descriptor { return s.DescribeTag("") }
+
+// DescribeTag returns the select entry field descriptor with options from the
+// struct field tag.
+func (s SelectEntry) DescribeTag(tag reflect.StructTag) Descriptor {
+	return Descriptor{Kind: KindSelectEntry, Options: TagOptions(tag)}
+}
+
+// Validate accepts any value, options of the select entry are suggestions.
+func (s SelectEntry) Validate() error { return nil }
+
+// ValidateTag accepts any value, options from the struct field tag are
+// suggestions.
+func (s SelectEntry) ValidateTag(reflect.StructTag) error { return nil }
+
+// options contains registered options providers by name.
+var options struct {
+	sync.RWMutex
+	providers map[string]func() []string
+}
+
+// RegisterOptions registers the options provider by name. The provider is
+// called every time the field is described, so it may return options which
+// change at runtime, e.g. network interfaces or files in a directory.
+func RegisterOptions(name string, provider func() []string) {
+	options.Lock()
+	defer options.Unlock()
+
+	if options.providers == nil {
+		options.providers = make(map[string]func() []string)
+	}
+	options.providers[name] = provider
+}
+
+// Options returns options of the registered options provider by name and
+// true, or false if the provider is not registered.
+func Options(name string) ([]string, bool) {
+	options.RLock()
+	provider, ok := options.providers[name]
+	options.RUnlock()
+	if !ok {
+		return nil, false
+	}
+	return provider(), true
+}
+
+// TagOptions returns options from the "options" struct field tag. The tag
+// contains comma separated options or the registered options provider name
+// with "@" prefix.
+func TagOptions(tag reflect.StructTag) []string {
+	value := tag.Get("options")
+	if name, ok := strings.CutPrefix(value, "@"); ok {
+		opts, _ := Options(name)
+		return opts
+	}
+	if value == "" {
+		return nil
+	}
+	opts := strings.Split(value, ",")
+	for i := range opts {
+		opts[i] = strings.TrimSpace(opts[i])
+	}
+	return opts
+}
+
+// RegisterEnum registers the string type T with the list of allowed values as
+// the special field type. Fields of type T are edited as Select with the
+// values as options and validated by conf.Validate to be one of the values:
+//
+//	type Level string
+//	const (Debug Level = "debug"; Info Level = "info")
+//	types.RegisterEnum(Debug, Info)
+func RegisterEnum[T ~string](values ...T) {
+	opts := make([]string, len(values))
+	for i, v := range values {
+		opts[i] = string(v)
+	}
+	t := reflect.TypeOf((*T)(nil)).Elem()
+	conf.RegisterValidator(t, func(v any, _ reflect.StructTag) error {
+		return checkOption(opts, string(v.(T)))
+	})
+	register(t, registered{
+		describe: func(any, reflect.StructTag, string) Descriptor {
+			return Descriptor{
+				Kind:    KindSelect,
+				Options: opts,
+				Validate: func(val string) error {
+					return checkOption(opts, val)
+				},
+			}
+		},
+		getValue: func(value any) string { return string(value.(T)) },
+		setValue: func(_ any, val string) any { return T(val) },
+	})
+}
+
+// checkOption checks the value is one of options. Any value is valid if there
+// are no options.
+func checkOption(options []string, val string) error {
+	if len(options) > 0 && !slices.Contains(options, val) {
+		return fmt.Errorf("%q is not one of options", val)
+	}
+	return nil
+}
@@ -0,0 +1,78 @@
+package types
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/teonet-go/conf"
+)
+
+type selectLevel string
+
+func TestSelect(t *testing.T) {
+
+	RegisterOptions("selectTest", func() []string { return []string{"x", "y"} })
+	for _, test := range []struct {
+		tag     reflect.StructTag
+		options []string
+	}{
+		{``, nil},
+		{`options:"a, b,c"`, []string{"a", "b", "c"}},
+		{`options:"@selectTest"`, []string{"x", "y"}},
+		{`options:"@unknown"`, nil},
+	} {
+		if opts := TagOptions(test.tag); !reflect.DeepEqual(opts,
+			test.options) {
+			t.Fatalf("%s: got options %v, want %v", test.tag, opts,
+				test.options)
+		}
+	}
+
+	// Values are validated against the tag options
+	type config struct {
+		Level  Select            `options:"debug,info"`
+		Iface  Select            `options:"@selectTest"`
+		Any    Select            ``
+		Name   SelectEntry       `options:"a,b"`
+		Levels map[string]Select `options:"debug,info"`
+	}
+	valid := config{Level: "info", Iface: "x", Any: "z", Name: "c",
+		Levels: map[string]Select{"app": "debug"}}
+	if err := conf.Validate(valid); err != nil {
+		t.Fatal(err)
+	}
+	for name, change := range map[string]func(c *config){
+		"select":          func(c *config) { c.Level = "trace" },
+		"provider select": func(c *config) { c.Iface = "z" },
+		"map select": func(c *config) {
+			c.Levels = map[string]Select{"app": "trace"}
+		},
+	} {
+		c := valid
+		change(&c)
+		if conf.Validate(c) == nil {
+			t.Fatalf("%s: invalid value should be an error", name)
+		}
+	}
+	d := Select("").DescribeTag(`options:"debug,info"`)
+	if d.Kind != KindSelect || d.Validate("info") != nil ||
+		d.Validate("trace") == nil {
+		t.Fatalf("wrong select descriptor %+v", d)
+	}
+
+	// Enum types are edited as select and validated by conf.Validate
+	RegisterEnum[selectLevel]("debug", "info")
+	d, ok := Describe(selectLevel("info"))
+	if !ok || d.Kind != KindSelect || len(d.Options) != 2 {
+		t.Fatalf("wrong enum descriptor %+v", d)
+	}
+	if d.Validate("trace") == nil {
+		t.Fatal("unknown enum value should be an error")
+	}
+	if err := conf.Validate(struct{ Level selectLevel }{"info"}); err != nil {
+		t.Fatal(err)
+	}
+	if conf.Validate(struct{ Level selectLevel }{"trace"}) == nil {
+		t.Fatal("unknown enum value should be an error")
+	}
+}
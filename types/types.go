@@ -1,74 +1,140 @@
+// Copyright 2024 Kirill Scherba <kirill@scherba.ru>. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package types contains special config field types. The types describe how
+// their fields are edited with renderer-neutral descriptors and don't depend
+// on any UI toolkit, so they may be used in headless servers and rendered by
+// Fyne forms, TUI, web forms or CLI.
 package types
 
-import (
-	"fyne.io/fyne/v2"
-	"fyne.io/fyne/v2/widget"
-	"github.com/teonet-go/conf"
-)
+import "github.com/teonet-go/conf"
 
 // Types interface type.
 type Types[T any] interface {
 	GetValue() string
 	SetValue(val string) T
-	NewWidget() (fyne.CanvasObject, bool)
-	GetWidgetValue(field *conf.Field[fyne.CanvasObject]) string
+	Describe() Descriptor
 }
 
-// GetValue returns the value of the GetValueType.
-func GetValue[T Types[T]](val T) string { return val.GetValue() }
+// Kind is a kind of the field editor. Renderers create field editors by kind.
+// Application packages may define their own kinds.
+type Kind string
+
+// Field editor kinds.
+const (
+	KindText        Kind = "text"         // Single line text
+	KindEmail       Kind = "email"        // Email address
+	KindPassword    Kind = "password"     // Hidden text
+	KindMultiline   Kind = "multiline"    // Multiline text
+	KindRadio       Kind = "radio"        // One of options
+	KindSelect      Kind = "select"       // One of options in drop-down list
+	KindSelectEntry Kind = "select_entry" // Text or one of options
+	KindCheckGroup  Kind = "check_group"  // Set of options
+	KindURL         Kind = "url"          // Absolute URL
+	KindIP          Kind = "ip"           // IP address
+	KindCIDR        Kind = "cidr"         // IP network prefix
+	KindHostPort    Kind = "host_port"    // Host and port
+	KindPort        Kind = "port"         // Port number
+	KindFilePath    Kind = "file_path"    // Path to file
+	KindDirPath     Kind = "dir_path"     // Path to directory
+	KindColor       Kind = "color"        // Color
+	KindRange       Kind = "range"        // Number within bounds
+	KindKeyValue    Kind = "key_value"    // Key and value pairs
+)
+
+// Descriptor describes how the special field is edited.
+type Descriptor struct {
+	Kind        Kind     // Editor kind
+	Options     []string // Options to select from
+	Horizontal  bool     // Options are placed horizontally
+	Placeholder string   // Text shown in empty editor
+	Rows        int      // Number of visible multiline text rows
+	Secret      bool     // Value is sensitive and should be hidden
+	Hint        bool     // Field type hint should be shown
+	Extensions  []string // Allowed file extensions
+	BaseDir     string   // Base directory of relative paths
+	Min         float64  // Minimum number
+	Max         float64  // Maximum number
+	Step        float64  // Number step, zero is any step
 
-// GetWidgetValue returns the widget value.
-func GetWidgetValue[T Types[T]](val T, field *conf.Field[fyne.CanvasObject]) string {
-	return val.GetWidgetValue(field)
+	// Validate checks the value entered in the editor, it may be nil
+	Validate func(s string) error
 }
 
+// GetValue returns the value of the GetValueType.
+func GetValue[T Types[T]](val T) string { return val.GetValue() }
+
 // SetValue sets the value to field.Value.
 func SetValue[T Types[T], F any](field *conf.Field[F], val string) {
 	field.Value = field.Value.(T).SetValue(val)
 }
 
-// NewWidget creates and returns widget and true if the field type is supported.
-func NewWidget[T Types[T], F any](field *conf.Field[F]) (fyne.CanvasObject, bool) {
-	return field.Value.(T).NewWidget()
+// Describe returns the descriptor of the registered special field type value
+// and true, or false if the value type is not registered.
+func Describe(value any) (d Descriptor, ok bool) {
+	r, ok := lookup(value)
+	if !ok {
+		return
+	}
+	return r.describe(value, "", ""), true
+}
+
+// DescribeField returns the descriptor of the registered special field type
+// using the field struct tag and true, or false if the field type is not
+// registered.
+func DescribeField[F any](field *conf.Field[F]) (d Descriptor, ok bool) {
+	return DescribeFieldDir(field, "")
 }
 
-// CheckWidget creates and returns widget and true if the field type is supported.
-func CheckWidget(field *conf.Field[fyne.CanvasObject]) (w fyne.CanvasObject, h, ok bool) {
-	switch field.Type {
-	case "types.Email":
-		w, h = NewWidget[Email](field)
-	case "types.RadioGroup":
-		w, h = NewWidget[RadioGroup](field)
-	case "types.Password":
-		w, h = NewWidget[Password](field)
-	case "types.Multiline":
-		w, h = NewWidget[Multiline](field)
-	default:
+// DescribeFieldDir is like DescribeField but relative paths are resolved
+// against the config file directory dir.
+func DescribeFieldDir[F any](field *conf.Field[F], dir string) (d Descriptor,
+	ok bool) {
+
+	r, ok := lookup(field.Value)
+	if !ok {
 		return
 	}
-	ok = true
-	return
+	return r.describe(field.Value, field.Tag, dir), true
+}
+
+// GetFieldValue returns the string value of the registered special field type
+// and true, or false if the field type is not registered.
+func GetFieldValue[F any](field *conf.Field[F]) (val string, ok bool) {
+	r, ok := lookup(field.Value)
+	if !ok {
+		return
+	}
+	return r.getValue(field.Value), true
 }
 
-// CheckSave checks if the field type is supported get widget value and set it
-// to field using SetValue.
-func CheckSave(field *conf.Field[fyne.CanvasObject]) (ok bool) {
-	switch field.Type {
-	case "types.Email":
-		val := field.Entry.(*widget.Entry).Text
-		SetValue[Email](field, val)
-	case "types.Password":
-		val := field.Entry.(*widget.Entry).Text
-		SetValue[Password](field, val)
-	case "types.Multiline":
-		val := field.Entry.(*widget.Entry).Text
-		SetValue[Multiline](field, val)
-	case "types.RadioGroup":
-		val := field.Entry.(*widget.RadioGroup).Selected
-		SetValue[RadioGroup](field, val)
-	default:
+// SetFieldValue sets the string value to the field of the registered special
+// field type and returns true, or returns false if the field type is not
+// registered.
+func SetFieldValue[F any](field *conf.Field[F], val string) (ok bool) {
+	r, ok := lookup(field.Value)
+	if !ok {
 		return
 	}
-	ok = true
+	field.Value = r.setValue(field.Value, val)
 	return
 }
+
+// SetFieldValueE is like SetFieldValue but checks the value with the field
+// descriptor Validate function first. The invalid value is not set and the
+// validation error is returned.
+func SetFieldValueE[F any](field *conf.Field[F], val string) (ok bool,
+	err error) {
+
+	d, ok := DescribeField(field)
+	if !ok {
+		return
+	}
+	if d.Validate != nil {
+		if err = d.Validate(val); err != nil {
+			return
+		}
+	}
+	return SetFieldValue(field, val), nil
+}
@@ -0,0 +1,66 @@
+package types
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/teonet-go/conf"
+)
+
+func TestValidate(t *testing.T) {
+
+	for _, test := range []struct {
+		value interface{ Validate() error }
+		valid bool
+	}{
+		{Email(""), true},
+		{Email("user@example.com"), true},
+		{Email("User <user@example.com>"), true},
+		{Email("user"), false},
+		{Multiline{Value: "a\nb", MaxLines: 2}, true},
+		{Multiline{Value: "a\nb\nc", MaxLines: 2}, false},
+		{Multiline{Value: "a\nb\nc"}, true},
+		{Multiline{MultiLineRows: -1}, false},
+		{RadioGroup{}, true},
+		{RadioGroup{Options: []string{"a", "b"}, Selected: 1}, true},
+		{RadioGroup{Options: []string{"a", "b"}, Selected: 2}, false},
+		{RadioGroup{Options: []string{"a", "b"}, Selected: -1}, false},
+		{Password(""), true},
+	} {
+		if err := test.value.Validate(); (err == nil) != test.valid {
+			t.Fatalf("%T %v: got error %v, want valid %v", test.value,
+				test.value, err, test.valid)
+		}
+	}
+
+	// Password policy
+	policy := PasswordPolicy{MinLength: 8, RequireUpper: true,
+		RequireDigit: true}
+	for password, valid := range map[string]bool{
+		"Secret12": true,
+		"Secret1":  false,
+		"secret12": false,
+		"SecretAB": false,
+	} {
+		if err := policy.Check(password); (err == nil) != valid {
+			t.Fatalf("%s: got error %v, want valid %v", password, err, valid)
+		}
+	}
+
+	// Headless validation of nested special types
+	type config struct {
+		Email Email
+		Users []struct{ Email Email }
+	}
+	c := config{Email: "user@example.com"}
+	c.Users = append(c.Users, struct{ Email Email }{"user"})
+	err := conf.Validate(c)
+	var pe *conf.PathError
+	if !errors.As(err, &pe) || pe.Path != "/Users/0/Email" {
+		t.Fatalf("got error %v, want /Users/0/Email error", err)
+	}
+	c.Users[0].Email = "admin@example.com"
+	if err = conf.Validate(c); err != nil {
+		t.Fatal(err)
+	}
+}
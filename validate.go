@@ -0,0 +1,153 @@
+// Copyright 2024 Kirill Scherba <kirill@scherba.ru>. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Config helper go package. Validate module validates config values.
+
+package conf
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"sync"
+)
+
+// Validator is implemented by config values which can validate themselves.
+type Validator interface {
+	Validate() error
+}
+
+// TagValidator is implemented by config values which validation depends on
+// the struct field tag, e.g. options or bounds set in the tag. Validate calls
+// ValidateTag instead of Validate with the tag of the struct field which
+// contains the value. Map values and slice elements get the tag of the field
+// which contains the map or the slice.
+type TagValidator interface {
+	ValidateTag(tag reflect.StructTag) error
+}
+
+// DirValidator is implemented by config values which validation depends on
+// the config file directory and the struct field tag, e.g. paths relative to
+// the config file. ValidateDir calls ValidateDir instead of ValidateTag and
+// Validate with the directory.
+type DirValidator interface {
+	ValidateDir(tag reflect.StructTag, dir string) error
+}
+
+// validators contains registered validation functions by value type.
+var validators sync.Map // map[reflect.Type]func(any, reflect.StructTag) error
+
+// RegisterValidator registers the validation function of values of type t.
+// It is used to validate types which can't implement Validator or
+// TagValidator, e.g. enum types declared in other packages. The function gets
+// the value and the struct field tag. Validate calls the registered function
+// if the value does not implement Validator and TagValidator.
+func RegisterValidator(t reflect.Type, f func(v any, tag reflect.StructTag) error) {
+	validators.Store(t, f)
+}
+
+// Validate validates the config value v without GUI. The value and all its
+// nested fields, map values and slice elements which implement Validator,
+// TagValidator or DirValidator are validated. Errors of nested values are
+// returned as *PathError with the JSON Pointer path of the value made of field
+// names and joined to the returned error.
+func Validate(v any) error { return ValidateDir(v, "") }
+
+// ValidateDir is like Validate but values implementing DirValidator are
+// validated with the config file directory dir, so relative paths are
+// resolved against it. The empty dir is the current directory.
+func ValidateDir(v any, dir string) (err error) {
+	var errs []error
+	validateValue(reflect.ValueOf(v), "", "", dir, make(map[uintptr]bool),
+		&errs)
+	err = errors.Join(errs...)
+	trace("validate config", "type", fmt.Sprintf("%T", v), "error", err)
+	return
+}
+
+// validateValue validates the value with the struct field tag and the config
+// file directory and its nested values and appends errors to errs. The
+// visited pointers are skipped.
+func validateValue(v reflect.Value, path string, tag reflect.StructTag,
+	dir string, visited map[uintptr]bool, errs *[]error) {
+
+	if !v.IsValid() {
+		return
+	}
+
+	// Validate value, pointers and interfaces are validated by their elements
+	if v.Kind() != reflect.Ptr && v.Kind() != reflect.Interface &&
+		v.CanInterface() {
+		if err := validateOne(v, tag, dir); err != nil {
+			if path != "" {
+				err = &PathError{path, err}
+			}
+			*errs = append(*errs, err)
+		}
+	}
+
+	// Validate nested values
+	switch v.Kind() {
+	case reflect.Ptr:
+		if v.IsNil() || visited[v.Pointer()] {
+			return
+		}
+		visited[v.Pointer()] = true
+		validateValue(v.Elem(), path, tag, dir, visited, errs)
+
+	case reflect.Interface:
+		if !v.IsNil() {
+			validateValue(v.Elem(), path, tag, dir, visited, errs)
+		}
+
+	case reflect.Struct:
+		for _, f := range planOf(v.Type()).fields {
+			validateValue(v.Field(f.index), path+"/"+escapePathSegment(f.name),
+				f.tag, dir, visited, errs)
+		}
+
+	case reflect.Map:
+		iter := v.MapRange()
+		for iter.Next() {
+			validateValue(iter.Value(),
+				path+"/"+escapePathSegment(fmt.Sprint(iter.Key())), tag, dir,
+				visited, errs)
+		}
+
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			validateValue(v.Index(i), fmt.Sprintf("%s/%d", path, i), tag, dir,
+				visited, errs)
+		}
+	}
+}
+
+// validateOne validates the value with ValidateDir, ValidateTag or Validate
+// method of the value or of the pointer to it, or with the registered
+// validation function.
+func validateOne(v reflect.Value, tag reflect.StructTag, dir string) error {
+	vals := []any{v.Interface()}
+	if v.CanAddr() && v.Addr().CanInterface() {
+		vals = append(vals, v.Addr().Interface())
+	}
+	for _, val := range vals {
+		if dv, ok := val.(DirValidator); ok {
+			return dv.ValidateDir(tag, dir)
+		}
+	}
+	for _, val := range vals {
+		if tv, ok := val.(TagValidator); ok {
+			return tv.ValidateTag(tag)
+		}
+	}
+	for _, val := range vals {
+		if tv, ok := val.(Validator); ok {
+			return tv.Validate()
+		}
+	}
+	if f, ok := validators.Load(v.Type()); ok {
+		return f.(func(any, reflect.StructTag) error)(vals[0], tag)
+	}
+	return nil
+}
@@ -0,0 +1,122 @@
+package conf
+
+import (
+	"errors"
+	"reflect"
+	"strconv"
+	"testing"
+)
+
+type validatePort int
+
+func (p validatePort) Validate() error {
+	if p < 1 || p > 65535 {
+		return errors.New("invalid port")
+	}
+	return nil
+}
+
+type validateLimit int
+
+func (l validateLimit) Validate() error { return l.ValidateTag("") }
+
+func (l validateLimit) ValidateTag(tag reflect.StructTag) error {
+	if max, err := strconv.Atoi(tag.Get("max")); err == nil && int(l) > max {
+		return errors.New("value is above max")
+	}
+	return nil
+}
+
+func TestValidate(t *testing.T) {
+
+	type server struct {
+		Port validatePort
+	}
+	type config struct {
+		Server  server
+		Backup  *server
+		Servers []server
+		Ports   map[string]validatePort
+	}
+
+	c := config{
+		Server:  server{80},
+		Backup:  &server{0},
+		Servers: []server{{443}, {70000}},
+		Ports:   map[string]validatePort{"a/b": -1},
+	}
+	err := Validate(&c)
+	if err == nil {
+		t.Fatal("invalid ports should be an error")
+	}
+	var paths []string
+	for _, e := range err.(interface{ Unwrap() []error }).Unwrap() {
+		var pe *PathError
+		if !errors.As(e, &pe) {
+			t.Fatalf("error %v should be PathError", e)
+		}
+		paths = append(paths, pe.Path)
+	}
+	want := []string{"/Backup/Port", "/Servers/1/Port", "/Ports/a~1b"}
+	if len(paths) != len(want) {
+		t.Fatalf("got paths %v, want %v", paths, want)
+	}
+	for i := range want {
+		if paths[i] != want[i] {
+			t.Fatalf("got paths %v, want %v", paths, want)
+		}
+	}
+
+	c.Backup.Port, c.Servers[1].Port, c.Ports["a/b"] = 8080, 8443, 1
+	if err = Validate(c); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestValidateTag(t *testing.T) {
+
+	type config struct {
+		Limit  validateLimit            `max:"10"`
+		Limits []validateLimit          `max:"5"`
+		ByName map[string]validateLimit `max:"1"`
+		Any    validateLimit
+	}
+
+	c := config{11, []validateLimit{1, 6}, map[string]validateLimit{"a": 2},
+		100}
+	err := Validate(&c)
+	if err == nil {
+		t.Fatal("values above tag limits should be an error")
+	}
+	var paths []string
+	for _, e := range err.(interface{ Unwrap() []error }).Unwrap() {
+		var pe *PathError
+		if errors.As(e, &pe) {
+			paths = append(paths, pe.Path)
+		}
+	}
+	want := []string{"/Limit", "/Limits/1", "/ByName/a"}
+	if !reflect.DeepEqual(paths, want) {
+		t.Fatalf("got paths %v, want %v", paths, want)
+	}
+}
+
+type validateRelPath string
+
+func (p validateRelPath) ValidateDir(_ reflect.StructTag, dir string) error {
+	if dir != "configs" {
+		return errors.New("wrong directory " + dir)
+	}
+	return nil
+}
+
+func TestValidateDir(t *testing.T) {
+
+	c := struct{ Path validateRelPath }{"cert.pem"}
+	if err := NewFile("configs/config.json").Validate(c); err != nil {
+		t.Fatal(err)
+	}
+	if err := Validate(c); err == nil {
+		t.Fatal("path should be validated with the empty directory")
+	}
+}
@@ -0,0 +1,150 @@
+// Copyright 2024 Kirill Scherba <kirill@scherba.ru>. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Config helper go package. Watch module watches config files and reloads
+// them on change.
+
+package conf
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"reflect"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// WatchDelay is the time Watch waits after the last config file event before
+// it reloads the file. Editors and File.Save make several file system events
+// for one file change.
+const WatchDelay = 100 * time.Millisecond
+
+// Watch watches the config file and reloads it to the value pointed to by ptr
+// when the file changes.
+//
+// The directory of the file is watched, so files replaced by rename, as many
+// editors and File.Save do, are reloaded too. The file is decoded to a new
+// value which is validated if it implements the Validator interface. Only
+// valid values with changed fields are stored to ptr. After the value is
+// stored onChange is called with the names of the changed top-level fields
+// and field subscribers added with Subscribe are notified.
+// Load and validation errors are reported to onChange with nil fields and the
+// ptr value stays unchanged.
+//
+// Watch returns after the watcher is started. The file is watched until the
+// context is done. The ptr value is changed and onChange is called from the
+// watcher goroutine, so concurrent readers of ptr should be synchronized with
+// it, or the Store should be used.
+func Watch(ctx context.Context, path string, ptr any,
+	onChange func(fields []string, err error)) (err error) {
+
+	if reflect.TypeOf(ptr).Kind() != reflect.Ptr {
+		return fmt.Errorf("ptr parameter of Watch should be a pointer")
+	}
+
+	watcher, err := newWatcher(path)
+	if err != nil {
+		return
+	}
+
+	// Store changed value to ptr and notify subscribers
+	store := func(v any) (fields []string, err error) {
+		changes := fieldChanges(ptr, v)
+		if len(changes) == 0 {
+			return
+		}
+		reflect.ValueOf(ptr).Elem().Set(reflect.ValueOf(v).Elem())
+		notify(ptr, changes...)
+		return changePaths(changes), nil
+	}
+
+	go watch(ctx, watcher, NewFile(path), reflect.TypeOf(ptr).Elem(), store,
+		onChange)
+	return
+}
+
+// newWatcher creates fsnotify watcher of the config file directory.
+func newWatcher(path string) (watcher *fsnotify.Watcher, err error) {
+	if watcher, err = fsnotify.NewWatcher(); err != nil {
+		return
+	}
+	if err = watcher.Add(filepath.Dir(path)); err != nil {
+		watcher.Close()
+		return nil, err
+	}
+	return
+}
+
+// watch processes watcher events until the context is done. The config file
+// is reloaded to a new value of type t and the store function stores it.
+func watch(ctx context.Context, watcher *fsnotify.Watcher, file *File,
+	t reflect.Type, store func(v any) ([]string, error),
+	onChange func(fields []string, err error)) {
+
+	defer watcher.Close()
+
+	if onChange == nil {
+		onChange = func([]string, error) {}
+	}
+
+	name := filepath.Clean(file.Path)
+	timer := time.NewTimer(WatchDelay)
+	timer.Stop()
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+
+		// Debounce config file events
+		case event, ok := <-watcher.Events:
+			if !ok {
+				return
+			}
+			if filepath.Clean(event.Name) != name || event.Op == fsnotify.Chmod {
+				continue
+			}
+			timer.Reset(WatchDelay)
+
+		case err, ok := <-watcher.Errors:
+			if !ok {
+				return
+			}
+			onChange(nil, err)
+
+		// Reload config file
+		case <-timer.C:
+			fields, err := reload(ctx, file, t, store)
+			if err != nil || len(fields) > 0 {
+				onChange(fields, err)
+			}
+		}
+	}
+}
+
+// reload loads the config file to a new value of type t, validates it and
+// stores it with the store function. It returns names of the changed fields.
+func reload(ctx context.Context, file *File, t reflect.Type,
+	store func(v any) ([]string, error)) (fields []string, err error) {
+
+	v := reflect.New(t)
+	if err = file.LoadContext(ctx, v.Interface()); err != nil {
+		return
+	}
+	if err = file.Validate(v.Interface()); err != nil {
+		return
+	}
+	return store(v.Interface())
+}
+
+// changePaths returns paths of the changes.
+func changePaths(changes []Change) (paths []string) {
+	for _, c := range changes {
+		paths = append(paths, c.Path)
+	}
+	return
+}
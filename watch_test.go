@@ -0,0 +1,85 @@
+package conf
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+type watchConfig struct {
+	Name string `json:"name"`
+	Port int    `json:"port"`
+}
+
+func (c *watchConfig) Validate() error {
+	if c.Port <= 0 {
+		return errors.New("port should be positive")
+	}
+	return nil
+}
+
+func TestWatch(t *testing.T) {
+
+	path := filepath.Join(t.TempDir(), "config.json")
+	if err := os.WriteFile(path, []byte(`{"name": "a", "port": 80}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	var c watchConfig
+	if err := Load(path, &c); err != nil {
+		t.Fatal(err)
+	}
+
+	type change struct {
+		fields []string
+		err    error
+	}
+	changes := make(chan change, 1)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	err := Watch(ctx, path, &c, func(fields []string, err error) {
+		select {
+		case changes <- change{fields, err}:
+		default:
+		}
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	wait := func() change {
+		select {
+		case ch := <-changes:
+			return ch
+		case <-time.After(5 * time.Second):
+			t.Fatal("no change received")
+		}
+		return change{}
+	}
+
+	// Replace file by rename
+	if err = Save(path, watchConfig{"a", 8080}); err != nil {
+		t.Fatal(err)
+	}
+	ch := wait()
+	if ch.err != nil || len(ch.fields) != 1 || ch.fields[0] != "Port" {
+		t.Fatalf("wrong change: %v", ch)
+	}
+	if c.Port != 8080 {
+		t.Fatalf("port should be reloaded, got %d", c.Port)
+	}
+
+	// Invalid config is not stored
+	if err = os.WriteFile(path, []byte(`{"name": "b", "port": 0}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if ch = wait(); ch.err == nil {
+		t.Fatal("invalid config should be reported")
+	}
+	if c.Name != "a" || c.Port != 8080 {
+		t.Fatalf("invalid config should not be stored: %+v", c)
+	}
+}